@@ -155,6 +155,42 @@ func (c *OrbitCamera) GetViewMatrix() mgl.Mat4 {
 	return view
 }
 
+// CameraSnapshot is a copy of the state of an OrbitCamera that can be used
+// to put the camera back where it was.
+type CameraSnapshot struct {
+	// Target is the origin point of the camera.
+	Target mgl.Vec3
+
+	// VertAngle is the angle between the up vector and the camera.
+	VertAngle float32
+
+	// Distance is how far away the camera is from the target.
+	Distance float32
+
+	// Rotation is the angle of the camera along its orbit around the target.
+	Rotation float32
+}
+
+// SaveCameraState returns a snapshot of the current camera state.
+func (c *OrbitCamera) SaveCameraState() CameraSnapshot {
+	return CameraSnapshot{
+		Target:    c.target,
+		VertAngle: c.vertAngle,
+		Distance:  c.distance,
+		Rotation:  c.rotation,
+	}
+}
+
+// RestoreCameraState sets the camera to the state in the snapshot and
+// updates the internal data.
+func (c *OrbitCamera) RestoreCameraState(snapshot CameraSnapshot) {
+	c.target = snapshot.Target
+	c.vertAngle = snapshot.VertAngle
+	c.distance = snapshot.Distance
+	c.rotation = snapshot.Rotation
+	c.generatePosition()
+}
+
 // YawPitchCamera keeps track of the view rotation and position and provides
 // utility methods to generate a view matrix.
 // It provides a free-moving camera that is adjusted by yaw and pitch which,
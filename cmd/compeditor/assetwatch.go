@@ -0,0 +1,134 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	gui "github.com/tbogdala/eweygewey"
+
+	component "github.com/tbogdala/fizzle/component"
+)
+
+const (
+	assetWatchWindowID = "AssetWatch"
+	assetTimeFormat    = "15:04:05"
+)
+
+// doToggleAssetWatchWindow shows the asset watch window if it's hidden and
+// hides it if it's showing.
+func doToggleAssetWatchWindow() {
+	assetWindow := uiman.GetWindow(assetWatchWindowID)
+	if assetWindow != nil {
+		uiman.RemoveWindow(assetWindow)
+		return
+	}
+
+	assetWindow = uiman.NewWindow(assetWatchWindowID, 0.27, 0.30, 0.45, 0.28, func(wnd *gui.Window) {
+		renderAssetWatchPanel(wnd)
+	})
+	assetWindow.Title = "Loaded Assets"
+	assetWindow.ShowTitleBar = true
+	assetWindow.IsMoveable = true
+	assetWindow.IsScrollable = true
+	assetWindow.ShowScrollBar = true
+	assetWindow.AutoAdjustHeight = false
+}
+
+// renderAssetWatchPanel lists every file loaded by the component manager with
+// the time it was loaded and the time it was last modified on disk. Files that
+// changed after they were loaded are marked as stale.
+func renderAssetWatchPanel(wnd *gui.Window) {
+	loadedFiles := componentMan.GetLoadedFilePaths()
+	paths := make([]string, 0, len(loadedFiles))
+	staleCount := 0
+	for path, info := range loadedFiles {
+		paths = append(paths, path)
+		if info.IsStale() {
+			staleCount++
+		}
+	}
+	sort.Strings(paths)
+
+	reloadStale, _ := wnd.Button("assetReloadStaleButton", "Reload All Stale")
+	wnd.Text(fmt.Sprintf("%d files loaded, %d stale", len(paths), staleCount))
+	if reloadStale {
+		doReloadStaleAssets()
+	}
+
+	wnd.Separator()
+	wnd.RequestItemWidthMin(0.1)
+	wnd.Text("Type")
+	wnd.RequestItemWidthMin(0.2)
+	wnd.Text("Name")
+	wnd.RequestItemWidthMin(0.15)
+	wnd.Text("Loaded")
+	wnd.RequestItemWidthMin(0.15)
+	wnd.Text("Modified")
+	wnd.Text("File")
+
+	for _, path := range paths {
+		info := loadedFiles[path]
+		modified := "missing"
+		if !info.ModifiedTime.IsZero() {
+			modified = info.ModifiedTime.Format(assetTimeFormat)
+		}
+		if info.IsStale() {
+			modified += " STALE"
+		}
+
+		wnd.StartRow()
+		wnd.RequestItemWidthMin(0.1)
+		wnd.Text(info.Type)
+		wnd.RequestItemWidthMin(0.2)
+		wnd.Text(info.StorageName)
+		wnd.RequestItemWidthMin(0.15)
+		wnd.Text(info.LoadedTime.Format(assetTimeFormat))
+		wnd.RequestItemWidthMin(0.15)
+		wnd.Text(modified)
+		wnd.Text(path)
+	}
+}
+
+// doReloadStaleAssets reloads the component being edited if any of its files
+// are stale, reloads the stale files in the component manager and then updates
+// the child components shown so that they use the reloaded components.
+func doReloadStaleAssets() {
+	if isEditorComponentStale(componentMan.GetLoadedFilePaths()) {
+		closeAllMeshWindows()
+		doLoadComponentFile(flagComponentFile)
+	}
+
+	reloadedCount, errs := componentMan.ReloadStaleFiles()
+	for _, err := range errs {
+		fmt.Printf("%v", err)
+	}
+	fmt.Printf("Reloaded %d stale assets.\n", reloadedCount)
+
+	refreshedChildren := []*component.Component{}
+	for _, childRef := range theComponent.ChildReferences {
+		if childComp, okay := componentMan.GetComponent(childRef.File); okay {
+			refreshedChildren = append(refreshedChildren, childComp)
+			childRefFilenames[childRef.File] = childComp.Name
+		}
+	}
+	childComponents = refreshedChildren
+
+	// textures are reloaded in place so the preview needs to be drawn again
+	if materialPreview != nil {
+		materialPreview.MarkDirty()
+	}
+}
+
+// isEditorComponentStale returns true if the component file being edited or
+// one of the mesh files loaded for it is stale.
+func isEditorComponentStale(loadedFiles map[string]component.AssetFileInfo) bool {
+	for _, info := range loadedFiles {
+		if info.Type != component.AssetTypeTexture && info.StorageName == flagComponentFile && info.IsStale() {
+			return true
+		}
+	}
+	return false
+}
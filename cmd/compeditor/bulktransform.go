@@ -0,0 +1,293 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	gui "github.com/tbogdala/eweygewey"
+
+	component "github.com/tbogdala/fizzle/component"
+)
+
+const (
+	// BulkTranslate adds the bulk transform value to the mesh offsets.
+	BulkTranslate = 0
+
+	// BulkRotate rotates the meshes by the bulk transform value in degrees
+	// around the X, Y and Z axes, in that order.
+	BulkRotate = 1
+
+	// BulkScale multiplies the mesh scales by the bulk transform value.
+	BulkScale = 2
+
+	// BulkTransformCount is the number of bulk transform operations.
+	BulkTransformCount = 3
+)
+
+var (
+	bulkTransformNames = [BulkTransformCount]string{"Translate", "Rotate", "Scale"}
+
+	// bulkTransformValue is the vector the bulk transform operations use.
+	bulkTransformValue = mgl.Vec3{0, 0, 0}
+
+	// bulkTransformExpression is the custom expression typed in to compute
+	// the new offset of each mesh.
+	bulkTransformExpression = "x, y, z"
+)
+
+// applyBulkTransform performs the bulk transform operation on all of the meshes.
+// When rotating or scaling more than one mesh, the mesh offsets are transformed
+// around the component origin as well so the meshes keep their arrangement.
+func applyBulkTransform(meshes []*component.Mesh, op int, value mgl.Vec3) error {
+	transformOffsets := len(meshes) > 1
+	switch op {
+	case BulkTranslate:
+		for _, compMesh := range meshes {
+			compMesh.Offset = compMesh.Offset.Add(value)
+		}
+	case BulkRotate:
+		delta := mgl.QuatRotate(mgl.DegToRad(value[2]), gizmoAxes[2]).Mul(
+			mgl.QuatRotate(mgl.DegToRad(value[1]), gizmoAxes[1])).Mul(
+			mgl.QuatRotate(mgl.DegToRad(value[0]), gizmoAxes[0]))
+		for _, compMesh := range meshes {
+			rotateMesh(compMesh, delta, transformOffsets)
+		}
+	case BulkScale:
+		if value[0] <= 0.0 || value[1] <= 0.0 || value[2] <= 0.0 {
+			return fmt.Errorf("Bulk scale factors must be greater than 0.")
+		}
+		for _, compMesh := range meshes {
+			scaleMesh(compMesh, value, transformOffsets)
+		}
+	default:
+		return fmt.Errorf("Unknown bulk transform operation %d.", op)
+	}
+	return nil
+}
+
+// doBulkTransform applies the bulk transform operation to the selected mesh,
+// or to all meshes if none is selected, as a single undoable command.
+func doBulkTransform(op int, value mgl.Vec3) {
+	meshes := getTargetMeshes(&theComponent)
+	if len(meshes) == 0 {
+		return
+	}
+
+	oldTransforms := getMeshTransforms(meshes)
+	err := applyBulkTransform(meshes, op, value)
+	if err != nil {
+		fmt.Printf("Failed to %s the meshes: %v\n", bulkTransformNames[op], err)
+		return
+	}
+
+	cmd := &meshTransformCommand{
+		meshes:        meshes,
+		oldTransforms: oldTransforms,
+		newTransforms: getMeshTransforms(meshes),
+	}
+	editHistory.Push(cmd)
+}
+
+// bulkExpressionTerm computes one component of a mesh offset from the offset
+// the mesh had before the expression was applied.
+type bulkExpressionTerm func(offset mgl.Vec3) float32
+
+// parseBulkExpression parses a custom bulk transform expression made of three
+// comma separated terms that compute the new X, Y and Z offset of a mesh. The
+// terms can use x, y and z for the current offset, numbers, parentheses and
+// the + - * / operators, e.g. "x, y + 1, z * 2".
+func parseBulkExpression(text string) ([3]bulkExpressionTerm, error) {
+	var terms [3]bulkExpressionTerm
+	parts := strings.Split(text, ",")
+	if len(parts) != 3 {
+		return terms, fmt.Errorf("The expression needs three comma separated terms for x, y and z; found %d.", len(parts))
+	}
+
+	for i, part := range parts {
+		p := &bulkExpressionParser{text: part}
+		term, err := p.parseSum()
+		if err == nil && p.peek() != 0 {
+			err = fmt.Errorf("Unexpected '%c' at position %d.", p.peek(), p.pos+1)
+		}
+		if err != nil {
+			return terms, fmt.Errorf("Failed to parse term %d (%s): %v", i+1, strings.TrimSpace(part), err)
+		}
+		terms[i] = term
+	}
+	return terms, nil
+}
+
+// bulkExpressionParser is a recursive descent parser for one term of a custom
+// bulk transform expression.
+type bulkExpressionParser struct {
+	text string
+	pos  int
+}
+
+// peek skips spaces and returns the next character or 0 at the end.
+func (p *bulkExpressionParser) peek() byte {
+	for p.pos < len(p.text) && p.text[p.pos] == ' ' {
+		p.pos++
+	}
+	if p.pos >= len(p.text) {
+		return 0
+	}
+	return p.text[p.pos]
+}
+
+// parseSum parses terms separated by + and -.
+func (p *bulkExpressionParser) parseSum() (bulkExpressionTerm, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '+' || op == '-'; op = p.peek() {
+		p.pos++
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		if op == '+' {
+			left = func(v mgl.Vec3) float32 { return l(v) + right(v) }
+		} else {
+			left = func(v mgl.Vec3) float32 { return l(v) - right(v) }
+		}
+	}
+	return left, nil
+}
+
+// parseProduct parses factors separated by * and /.
+func (p *bulkExpressionParser) parseProduct() (bulkExpressionTerm, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '*' || op == '/'; op = p.peek() {
+		p.pos++
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		if op == '*' {
+			left = func(v mgl.Vec3) float32 { return l(v) * right(v) }
+		} else {
+			left = func(v mgl.Vec3) float32 { return l(v) / right(v) }
+		}
+	}
+	return left, nil
+}
+
+// parseFactor parses a number, a variable, a negated factor or an expression
+// in parentheses.
+func (p *bulkExpressionParser) parseFactor() (bulkExpressionTerm, error) {
+	c := p.peek()
+	switch {
+	case c == 0:
+		return nil, fmt.Errorf("Unexpected end of the term.")
+	case c == '-':
+		p.pos++
+		f, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return func(v mgl.Vec3) float32 { return -f(v) }, nil
+	case c == '(':
+		p.pos++
+		inner, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("Missing ')' at position %d.", p.pos+1)
+		}
+		p.pos++
+		return inner, nil
+	case c == 'x' || c == 'y' || c == 'z':
+		p.pos++
+		axis := int(c - 'x')
+		return func(v mgl.Vec3) float32 { return v[axis] }, nil
+	case (c >= '0' && c <= '9') || c == '.':
+		start := p.pos
+		for p.pos < len(p.text) && ((p.text[p.pos] >= '0' && p.text[p.pos] <= '9') || p.text[p.pos] == '.') {
+			p.pos++
+		}
+		n, err := strconv.ParseFloat(p.text[start:p.pos], 32)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid number %s.", p.text[start:p.pos])
+		}
+		value := float32(n)
+		return func(v mgl.Vec3) float32 { return value }, nil
+	default:
+		return nil, fmt.Errorf("Unexpected '%c' at position %d.", c, p.pos+1)
+	}
+}
+
+// applyBulkExpression sets the offset of each mesh to the result of the
+// expression terms evaluated with the mesh's current offset.
+func applyBulkExpression(meshes []*component.Mesh, terms [3]bulkExpressionTerm) {
+	for _, compMesh := range meshes {
+		old := compMesh.Offset
+		compMesh.Offset = mgl.Vec3{terms[0](old), terms[1](old), terms[2](old)}
+	}
+}
+
+// doBulkExpression applies the custom expression to the offsets of the selected
+// mesh, or of all meshes if none is selected, as a single undoable command.
+func doBulkExpression(text string) {
+	terms, err := parseBulkExpression(text)
+	if err != nil {
+		fmt.Printf("Failed to apply the bulk transform expression: %v\n", err)
+		return
+	}
+
+	meshes := getTargetMeshes(&theComponent)
+	if len(meshes) == 0 {
+		return
+	}
+
+	oldTransforms := getMeshTransforms(meshes)
+	applyBulkExpression(meshes, terms)
+	cmd := &meshTransformCommand{
+		meshes:        meshes,
+		oldTransforms: oldTransforms,
+		newTransforms: getMeshTransforms(meshes),
+	}
+	editHistory.Push(cmd)
+}
+
+// renderBulkTransform does the user interface for the bulk transform operations.
+func renderBulkTransform(wnd *gui.Window) {
+	wnd.Separator()
+	wnd.RequestItemWidthMin(textWidth)
+	wnd.Text("Bulk Transform")
+	wnd.DragSliderFloat("bulkTransformX", 0.1, &bulkTransformValue[0])
+	wnd.DragSliderFloat("bulkTransformY", 0.1, &bulkTransformValue[1])
+	wnd.DragSliderFloat("bulkTransformZ", 0.1, &bulkTransformValue[2])
+
+	wnd.StartRow()
+	wnd.RequestItemWidthMin(textWidth)
+	wnd.Text("")
+	for op := 0; op < BulkTransformCount; op++ {
+		apply, _ := wnd.Button(fmt.Sprintf("buttonBulkTransform%d", op), bulkTransformNames[op])
+		if apply {
+			doBulkTransform(op, bulkTransformValue)
+		}
+	}
+
+	// the custom expression computes the new offset of each mesh
+	wnd.StartRow()
+	wnd.RequestItemWidthMin(textWidth)
+	wnd.Text("Expression")
+	wnd.Editbox("bulkTransformExpression", &bulkTransformExpression)
+	applyExpression, _ := wnd.Button("buttonBulkTransformExpression", "Apply")
+	if applyExpression {
+		doBulkExpression(bulkTransformExpression)
+	}
+}
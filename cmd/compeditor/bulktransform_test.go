@@ -0,0 +1,147 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package main
+
+import (
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+
+	component "github.com/tbogdala/fizzle/component"
+)
+
+// createBulkTestMeshes returns two meshes offset along the X axis.
+func createBulkTestMeshes() []*component.Mesh {
+	a := new(component.Mesh)
+	a.Offset = mgl.Vec3{1, 0, 0}
+	a.Scale = mgl.Vec3{1, 1, 1}
+	b := new(component.Mesh)
+	b.Offset = mgl.Vec3{-1, 0, 0}
+	b.Scale = mgl.Vec3{1, 1, 1}
+	return []*component.Mesh{a, b}
+}
+
+func TestApplyBulkTransform(t *testing.T) {
+	meshes := createBulkTestMeshes()
+	err := applyBulkTransform(meshes, BulkTranslate, mgl.Vec3{0, 2, 0})
+	if err != nil || meshes[0].Offset != (mgl.Vec3{1, 2, 0}) || meshes[1].Offset != (mgl.Vec3{-1, 2, 0}) {
+		t.Errorf("Translating moved the meshes to %v and %v (%v)", meshes[0].Offset, meshes[1].Offset, err)
+	}
+
+	// scaling more than one mesh scales their offsets from the origin too
+	meshes = createBulkTestMeshes()
+	err = applyBulkTransform(meshes, BulkScale, mgl.Vec3{2, 2, 2})
+	if err != nil || meshes[0].Scale != (mgl.Vec3{2, 2, 2}) || meshes[0].Offset != (mgl.Vec3{2, 0, 0}) {
+		t.Errorf("Scaling got a scale of %v and offset of %v (%v)", meshes[0].Scale, meshes[0].Offset, err)
+	}
+
+	// rotating more than one mesh rotates their offsets around the origin too
+	meshes = createBulkTestMeshes()
+	err = applyBulkTransform(meshes, BulkRotate, mgl.Vec3{0, 0, 90})
+	if err != nil || !meshes[0].Offset.ApproxEqualThreshold(mgl.Vec3{0, 1, 0}, 1e-5) {
+		t.Errorf("Rotating moved the offset to %v (%v); expected {0, 1, 0}", meshes[0].Offset, err)
+	}
+	if meshes[0].RotationDegrees < 89.99 || meshes[0].RotationDegrees > 90.01 {
+		t.Errorf("Rotating set the mesh rotation to %f degrees; expected 90", meshes[0].RotationDegrees)
+	}
+
+	if err := applyBulkTransform(meshes, BulkScale, mgl.Vec3{1, 0, 1}); err == nil {
+		t.Errorf("A scale factor of zero should fail.")
+	}
+	if err := applyBulkTransform(meshes, BulkTransformCount, mgl.Vec3{1, 1, 1}); err == nil {
+		t.Errorf("An unknown operation should fail.")
+	}
+}
+
+func TestDoBulkTransformSelection(t *testing.T) {
+	editHistory = newUndoHistory()
+	meshes := createBulkTestMeshes()
+	theComponent = component.Component{Meshes: meshes}
+	defer func() {
+		theComponent = component.Component{}
+		activeMesh = nil
+	}()
+
+	// with a mesh selected only that mesh is transformed
+	activeMesh = meshes[0]
+	doBulkTransform(BulkTranslate, mgl.Vec3{0, 1, 0})
+	if meshes[0].Offset != (mgl.Vec3{1, 1, 0}) {
+		t.Errorf("The selected mesh was moved to %v; expected {1, 1, 0}", meshes[0].Offset)
+	}
+	if meshes[1].Offset != (mgl.Vec3{-1, 0, 0}) || meshes[1].Scale != (mgl.Vec3{1, 1, 1}) {
+		t.Errorf("The mesh that isn't selected was changed to offset %v scale %v", meshes[1].Offset, meshes[1].Scale)
+	}
+
+	// with nothing selected every mesh is transformed in one undo command
+	activeMesh = nil
+	doBulkTransform(BulkTranslate, mgl.Vec3{0, 0, 2})
+	if meshes[0].Offset != (mgl.Vec3{1, 1, 2}) || meshes[1].Offset != (mgl.Vec3{-1, 0, 2}) {
+		t.Errorf("Translating all meshes moved them to %v and %v", meshes[0].Offset, meshes[1].Offset)
+	}
+	editHistory.Undo()
+	if meshes[0].Offset != (mgl.Vec3{1, 1, 0}) || meshes[1].Offset != (mgl.Vec3{-1, 0, 0}) {
+		t.Errorf("Undoing the translation of all meshes left them at %v and %v", meshes[0].Offset, meshes[1].Offset)
+	}
+}
+
+func TestParseBulkExpression(t *testing.T) {
+	offset := mgl.Vec3{1, 2, 3}
+	tests := []struct {
+		text     string
+		expected mgl.Vec3
+	}{
+		{"x, y, z", mgl.Vec3{1, 2, 3}},
+		{"x, y + 1, z", mgl.Vec3{1, 3, 3}},
+		{"x*2, y - 0.5, -z", mgl.Vec3{2, 1.5, -3}},
+		{"(x + y) * 2, z / 2, 4", mgl.Vec3{6, 1.5, 4}},
+		{" z , x+y*z , -(x-y) ", mgl.Vec3{3, 7, 1}},
+	}
+	for _, test := range tests {
+		terms, err := parseBulkExpression(test.text)
+		if err != nil {
+			t.Errorf("parseBulkExpression(%q) failed: %v", test.text, err)
+			continue
+		}
+		result := mgl.Vec3{terms[0](offset), terms[1](offset), terms[2](offset)}
+		if !result.ApproxEqualThreshold(test.expected, 1e-5) {
+			t.Errorf("parseBulkExpression(%q) computed %v; expected %v", test.text, result, test.expected)
+		}
+	}
+
+	for _, text := range []string{"", "x, y", "x, y, z, x", "x, y +, z", "x, (y, z", "x, y, w", "x, y 2, z"} {
+		if _, err := parseBulkExpression(text); err == nil {
+			t.Errorf("parseBulkExpression(%q) should fail.", text)
+		}
+	}
+}
+
+func TestApplyBulkExpression(t *testing.T) {
+	editHistory = newUndoHistory()
+	meshes := createBulkTestMeshes()
+	theComponent = component.Component{Meshes: meshes}
+	activeMesh = meshes[1]
+	defer func() {
+		theComponent = component.Component{}
+		activeMesh = nil
+	}()
+
+	doBulkExpression("x * 3, y + 1, z")
+	if meshes[1].Offset != (mgl.Vec3{-3, 1, 0}) {
+		t.Errorf("The expression moved the selected mesh to %v; expected {-3, 1, 0}", meshes[1].Offset)
+	}
+	if meshes[0].Offset != (mgl.Vec3{1, 0, 0}) {
+		t.Errorf("The expression moved the mesh that isn't selected to %v", meshes[0].Offset)
+	}
+
+	// an invalid expression changes nothing
+	doBulkExpression("x, y")
+	if meshes[1].Offset != (mgl.Vec3{-3, 1, 0}) {
+		t.Errorf("An invalid expression moved the mesh to %v", meshes[1].Offset)
+	}
+
+	editHistory.Undo()
+	if meshes[1].Offset != (mgl.Vec3{-1, 0, 0}) {
+		t.Errorf("Undoing the expression left the mesh at %v", meshes[1].Offset)
+	}
+}
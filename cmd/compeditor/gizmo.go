@@ -0,0 +1,500 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package main
+
+import (
+	"fmt"
+	"math"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+
+	fizzle "github.com/tbogdala/fizzle"
+	component "github.com/tbogdala/fizzle/component"
+	forward "github.com/tbogdala/fizzle/renderer/forward"
+)
+
+const (
+	// TransformNone hides the gizmo.
+	TransformNone = 0
+
+	// TransformRotate shows the rotation arcs of the gizmo.
+	TransformRotate = 1
+
+	// TransformScale shows the scale boxes of the gizmo.
+	TransformScale = 2
+
+	// TransformModeCount is the number of gizmo modes supported.
+	TransformModeCount = 3
+)
+
+const (
+	// ScaleHandleUniform is the index of the center scale handle which scales
+	// all axes proportionally. Handles 0-5 are the +X, -X, +Y, -Y, +Z and -Z boxes.
+	ScaleHandleUniform = 6
+
+	// ScaleHandleCount is the number of scale handles the gizmo has.
+	ScaleHandleCount = 7
+)
+
+const (
+	gizmoRingRadius        = 1.0
+	gizmoRingTubeRadius    = 0.015
+	gizmoRingSegments      = 48
+	gizmoRingTubeSegments  = 8
+	gizmoRingPickTolerance = 0.08
+
+	gizmoScaleHandleSize     = 0.1
+	gizmoScaleHandleDistance = 1.0
+
+	// gizmoRotateDegreesPerPixel is how much a rotation arc turns for each
+	// pixel the mouse is dragged.
+	gizmoRotateDegreesPerPixel = 0.5
+
+	// gizmoScalePerPixel is how much the scale changes for each pixel the
+	// mouse is dragged.
+	gizmoScalePerPixel = 0.01
+
+	// gizmoMinScaleFactor is the smallest factor a single drag update can
+	// scale by, which keeps a fast drag from flipping or collapsing a mesh.
+	gizmoMinScaleFactor = 0.1
+)
+
+var (
+	gizmoModeNames    = [TransformModeCount]string{"None", "Rotate", "Scale"}
+	gizmoAxes         = [3]mgl.Vec3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	gizmoColors       = [3]mgl.Vec4{{1, 0, 0, 1}, {0, 1, 0, 1}, {0, 0, 1, 1}}
+	gizmoUniformColor = mgl.Vec4{0.8, 0.8, 0.8, 1}
+	gizmoDragColor    = mgl.Vec4{1, 1, 0, 1}
+)
+
+// Gizmo draws the transform handles for the component being edited and turns
+// mouse drags on the handles into transforms for the active mesh, or for all
+// of the meshes in the component if no mesh is active.
+type Gizmo struct {
+	// Mode is the type of transform the gizmo performs (e.g. TransformRotate).
+	Mode int
+
+	// ActiveAxis is the index of the handle being dragged or -1 if none is.
+	// For rotation this is the axis index and for scaling it is one of the
+	// scale handles (e.g. ScaleHandleUniform).
+	ActiveAxis int
+
+	// Location is the world-space center of the gizmo.
+	Location mgl.Vec3
+
+	// rotateHandles are the arc rings for the X, Y and Z axes.
+	rotateHandles [3]*fizzle.Renderable
+
+	// scaleGroup is the parent of the scale handle boxes and scaleLines is the
+	// parent of the axis lines the boxes sit at the end of.
+	scaleGroup *fizzle.Renderable
+	scaleLines *fizzle.Renderable
+
+	// scaleHandleSize is the size of the scale boxes on the axes.
+	scaleHandleSize float32
+
+	// colorShader is the flat color shader used to draw the handles.
+	colorShader *fizzle.RenderShader
+
+	// dragMeshes are the meshes being transformed by the current drag and
+	// dragStart are their transforms from before the drag started.
+	dragMeshes []*component.Mesh
+	dragStart  []meshTransform
+}
+
+// NewGizmo creates the renderables for the gizmo handles which are drawn using
+// the flat color shader specified.
+func NewGizmo(colorShader *fizzle.RenderShader) (*Gizmo, error) {
+	g := new(Gizmo)
+	g.ActiveAxis = -1
+	g.colorShader = colorShader
+
+	// the torus is generated around the Y axis so rotate it to match each axis
+	ringRotations := [3]mgl.Quat{
+		mgl.QuatRotate(mgl.DegToRad(-90.0), mgl.Vec3{0, 0, 1}),
+		mgl.QuatIdent(),
+		mgl.QuatRotate(mgl.DegToRad(90.0), mgl.Vec3{1, 0, 0}),
+	}
+	torusMesh, err := component.CreateTorus(gizmoRingRadius, gizmoRingTubeRadius, gizmoRingSegments, gizmoRingTubeSegments)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create the gizmo rotation arcs: %v", err)
+	}
+	for axis := range g.rotateHandles {
+		r := fizzle.CreateFromGombz(torusMesh)
+		r.Material = fizzle.NewMaterial()
+		r.Material.Shader = colorShader
+		r.Material.DiffuseColor = gizmoColors[axis]
+		r.LocalRotation = ringRotations[axis]
+		g.rotateHandles[axis] = r
+	}
+
+	g.CreateScaleHandles(gizmoScaleHandleSize)
+
+	return g, nil
+}
+
+// CreateScaleHandles creates the box handles of the scale mode with the size
+// specified: one at each end of the X, Y and Z axis lines and a larger cube
+// at the center for uniform scaling. Any existing scale handles are destroyed.
+func (g *Gizmo) CreateScaleHandles(size float32) {
+	g.destroyScaleHandles()
+	g.scaleHandleSize = size
+
+	g.scaleGroup = fizzle.NewRenderable()
+	g.scaleGroup.IsGroup = true
+	g.scaleLines = fizzle.NewRenderable()
+	g.scaleLines.IsGroup = true
+
+	for handle := 0; handle < ScaleHandleCount; handle++ {
+		halfSize := g.getScaleHandleHalfSize(handle)
+		box := fizzle.CreateCube(-halfSize, -halfSize, -halfSize, halfSize, halfSize, halfSize)
+		box.Material = fizzle.NewMaterial()
+		box.Material.Shader = g.colorShader
+		box.Location = getScaleHandleOffset(handle)
+		g.scaleGroup.AddChild(box)
+	}
+
+	for axis := range gizmoAxes {
+		lineEnd := gizmoAxes[axis].Mul(gizmoScaleHandleDistance)
+		line := fizzle.CreateLineV(lineEnd.Mul(-1.0), lineEnd)
+		line.Material = fizzle.NewMaterial()
+		line.Material.Shader = g.colorShader
+		line.Material.DiffuseColor = gizmoColors[axis]
+		g.scaleLines.AddChild(line)
+	}
+}
+
+// destroyScaleHandles releases the renderables for the scale handles.
+func (g *Gizmo) destroyScaleHandles() {
+	for _, group := range []*fizzle.Renderable{g.scaleGroup, g.scaleLines} {
+		if group == nil {
+			continue
+		}
+		for _, r := range group.Children {
+			r.Destroy()
+		}
+	}
+	g.scaleGroup = nil
+	g.scaleLines = nil
+}
+
+// Destroy releases the renderables for the gizmo handles.
+func (g *Gizmo) Destroy() {
+	for _, r := range g.rotateHandles {
+		r.Destroy()
+	}
+	g.destroyScaleHandles()
+}
+
+// ModeName returns the user friendly name of the gizmo's current mode.
+func (g *Gizmo) ModeName() string {
+	return gizmoModeNames[g.Mode]
+}
+
+// IsDragging returns true if a handle of the gizmo is being dragged.
+func (g *Gizmo) IsDragging() bool {
+	return g.ActiveAxis >= 0
+}
+
+// getScaleHandleOffset returns the offset of the scale handle from the center
+// of the gizmo.
+func getScaleHandleOffset(handle int) mgl.Vec3 {
+	if handle == ScaleHandleUniform {
+		return mgl.Vec3{}
+	}
+	offset := gizmoAxes[handle/2].Mul(gizmoScaleHandleDistance)
+	if handle%2 == 1 {
+		offset = offset.Mul(-1.0)
+	}
+	return offset
+}
+
+// getScaleHandleHalfSize returns half of the width of the scale handle's box.
+func (g *Gizmo) getScaleHandleHalfSize(handle int) float32 {
+	if handle == ScaleHandleUniform {
+		return g.scaleHandleSize * 0.75
+	}
+	return g.scaleHandleSize * 0.5
+}
+
+// getScaleHandleColor returns the color of the scale handle when not dragged.
+func getScaleHandleColor(handle int) mgl.Vec4 {
+	if handle == ScaleHandleUniform {
+		return gizmoUniformColor
+	}
+	return gizmoColors[handle/2]
+}
+
+// Draw renders the handles for the gizmo's current mode at its location.
+func (g *Gizmo) Draw(renderer *forward.ForwardRenderer, perspective mgl.Mat4, view mgl.Mat4, camera fizzle.Camera) {
+	switch g.Mode {
+	case TransformRotate:
+		for axis, r := range g.rotateHandles {
+			r.Location = g.Location
+			r.Material.DiffuseColor = gizmoColors[axis]
+			if axis == g.ActiveAxis {
+				r.Material.DiffuseColor = gizmoDragColor
+			}
+			renderer.DrawRenderable(r, nil, perspective, view, camera)
+		}
+	case TransformScale:
+		g.scaleLines.Location = g.Location
+		renderer.DrawLines(g.scaleLines, g.colorShader, nil, perspective, view, camera)
+
+		g.scaleGroup.Location = g.Location
+		for handle, r := range g.scaleGroup.Children {
+			r.Material.DiffuseColor = getScaleHandleColor(handle)
+			if handle == g.ActiveAxis {
+				r.Material.DiffuseColor = gizmoDragColor
+			}
+		}
+		renderer.DrawRenderable(g.scaleGroup, nil, perspective, view, camera)
+	}
+}
+
+// PickAxis returns the index of the axis whose handle is hit by the ray
+// or -1 if no handle is hit.
+func (g *Gizmo) PickAxis(rayOrigin, rayDir mgl.Vec3) int {
+	switch g.Mode {
+	case TransformRotate:
+		return g.pickRotateArc(rayOrigin, rayDir)
+	case TransformScale:
+		return g.pickScaleHandle(rayOrigin, rayDir)
+	}
+	return -1
+}
+
+// pickScaleHandle intersects the ray with the box of each scale handle and
+// returns the closest handle hit.
+func (g *Gizmo) pickScaleHandle(rayOrigin, rayDir mgl.Vec3) int {
+	pickedHandle := -1
+	closestT := float32(math.MaxFloat32)
+	for handle := 0; handle < ScaleHandleCount; handle++ {
+		center := g.Location.Add(getScaleHandleOffset(handle))
+		halfSize := g.getScaleHandleHalfSize(handle)
+		t, hit := intersectRayBox(rayOrigin, rayDir, center, halfSize)
+		if hit && t < closestT {
+			pickedHandle = handle
+			closestT = t
+		}
+	}
+	return pickedHandle
+}
+
+// intersectRayBox tests the ray against an axis aligned cube using the slab
+// method and returns the distance along the ray to the hit.
+func intersectRayBox(rayOrigin, rayDir, center mgl.Vec3, halfSize float32) (float32, bool) {
+	tMin := float32(0.0)
+	tMax := float32(math.MaxFloat32)
+	for i := 0; i < 3; i++ {
+		boxMin := center[i] - halfSize
+		boxMax := center[i] + halfSize
+		if math.Abs(float64(rayDir[i])) < 1e-6 {
+			if rayOrigin[i] < boxMin || rayOrigin[i] > boxMax {
+				return 0.0, false
+			}
+			continue
+		}
+
+		t1 := (boxMin - rayOrigin[i]) / rayDir[i]
+		t2 := (boxMax - rayOrigin[i]) / rayDir[i]
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		if t1 > tMin {
+			tMin = t1
+		}
+		if t2 < tMax {
+			tMax = t2
+		}
+		if tMin > tMax {
+			return 0.0, false
+		}
+	}
+	return tMin, true
+}
+
+// pickRotateArc intersects the ray with the plane of each rotation arc and
+// returns the closest axis whose arc passes near the intersection point.
+func (g *Gizmo) pickRotateArc(rayOrigin, rayDir mgl.Vec3) int {
+	pickedAxis := -1
+	closestT := float32(math.MaxFloat32)
+	for axis, normal := range gizmoAxes {
+		denom := rayDir.Dot(normal)
+		if math.Abs(float64(denom)) < 1e-5 {
+			continue
+		}
+
+		t := g.Location.Sub(rayOrigin).Dot(normal) / denom
+		if t < 0.0 || t > closestT {
+			continue
+		}
+
+		hit := rayOrigin.Add(rayDir.Mul(t))
+		distFromRing := hit.Sub(g.Location).Len() - gizmoRingRadius
+		if math.Abs(float64(distFromRing)) <= gizmoRingPickTolerance {
+			pickedAxis = axis
+			closestT = t
+		}
+	}
+	return pickedAxis
+}
+
+// getTargetMeshes returns the meshes the gizmo transforms: the active mesh if it
+// belongs to the component or otherwise all of the component's meshes.
+func getTargetMeshes(comp *component.Component) []*component.Mesh {
+	for _, compMesh := range comp.Meshes {
+		if compMesh == activeMesh {
+			return []*component.Mesh{compMesh}
+		}
+	}
+	return comp.Meshes
+}
+
+// BeginDrag locks the gizmo to the axis specified and remembers the transforms
+// of the meshes that will be changed so the drag can be undone.
+func (g *Gizmo) BeginDrag(axis int, comp *component.Component) {
+	g.ActiveAxis = axis
+	g.dragMeshes = getTargetMeshes(comp)
+	g.dragStart = getMeshTransforms(g.dragMeshes)
+}
+
+// EndDrag unlocks the gizmo axis and pushes the transform done during the
+// drag onto the undo history. Nothing is pushed if the meshes didn't change,
+// such as when a handle was clicked without moving the mouse.
+func (g *Gizmo) EndDrag() {
+	if !g.IsDragging() {
+		return
+	}
+
+	newTransforms := getMeshTransforms(g.dragMeshes)
+	if !meshTransformsEqual(g.dragStart, newTransforms) {
+		cmd := &meshTransformCommand{
+			meshes:        g.dragMeshes,
+			oldTransforms: g.dragStart,
+			newTransforms: newTransforms,
+		}
+		editHistory.Push(cmd)
+	}
+
+	g.ActiveAxis = -1
+	g.dragMeshes = nil
+	g.dragStart = nil
+}
+
+// OnDrag applies the transform for the gizmo's current mode.
+func (g *Gizmo) OnDrag(dx, dy float32, comp *component.Component) {
+	switch g.Mode {
+	case TransformRotate:
+		g.OnRotateDrag(dx, dy, g.ActiveAxis, comp)
+	case TransformScale:
+		g.OnScaleDrag(dx, dy, g.ActiveAxis, comp)
+	}
+}
+
+// rotateDragToDegrees converts a mouse drag in pixels to the degrees to rotate.
+// Dragging right or up rotates in the positive direction.
+func rotateDragToDegrees(dx, dy float32) float32 {
+	return (dx - dy) * gizmoRotateDegreesPerPixel
+}
+
+// OnRotateDrag rotates the target meshes of the component around the active axis
+// by an amount based on the mouse drag in pixels. If the whole component is
+// being rotated, the mesh offsets are rotated around the component origin too.
+func (g *Gizmo) OnRotateDrag(dx, dy float32, activeAxis int, comp *component.Component) {
+	if activeAxis < 0 || activeAxis >= len(gizmoAxes) {
+		return
+	}
+
+	degrees := rotateDragToDegrees(dx, dy)
+	if degrees == 0.0 {
+		return
+	}
+	delta := mgl.QuatRotate(mgl.DegToRad(degrees), gizmoAxes[activeAxis])
+
+	targets := getTargetMeshes(comp)
+	rotateOffsets := len(targets) > 1 || activeMesh == nil
+	for _, compMesh := range targets {
+		rotateMesh(compMesh, delta, rotateOffsets)
+	}
+}
+
+// rotateMesh applies the rotation to the mesh on top of its current rotation.
+// If rotateOffset is true, the mesh offset is rotated around the component origin.
+func rotateMesh(compMesh *component.Mesh, delta mgl.Quat, rotateOffset bool) {
+	current := mgl.QuatIdent()
+	if compMesh.RotationDegrees != 0.0 && compMesh.RotationAxis.Len() > 0.0 {
+		current = mgl.QuatRotate(mgl.DegToRad(compMesh.RotationDegrees), compMesh.RotationAxis.Normalize())
+	}
+	compMesh.RotationAxis, compMesh.RotationDegrees = quatToAxisDegrees(delta.Mul(current))
+	if rotateOffset {
+		compMesh.Offset = delta.Rotate(compMesh.Offset)
+	}
+}
+
+// quatToAxisDegrees converts a rotation quaternion to an axis and an angle in degrees.
+func quatToAxisDegrees(q mgl.Quat) (mgl.Vec3, float32) {
+	q = q.Normalize()
+	w := math.Max(-1.0, math.Min(1.0, float64(q.W)))
+	s := math.Sqrt(1.0 - w*w)
+	if s < 1e-5 {
+		return mgl.Vec3{0, 1, 0}, 0.0
+	}
+
+	axis := q.V.Mul(float32(1.0 / s))
+	degrees := mgl.RadToDeg(float32(2.0 * math.Acos(w)))
+	return axis, degrees
+}
+
+// scaleDragToFactor converts a mouse drag in pixels to the factor to multiply
+// the scale by. Dragging right or up makes the mesh larger.
+func scaleDragToFactor(dx, dy float32) float32 {
+	factor := 1.0 + (dx-dy)*gizmoScalePerPixel
+	if factor < gizmoMinScaleFactor {
+		factor = gizmoMinScaleFactor
+	}
+	return factor
+}
+
+// OnScaleDrag scales the target meshes of the component along the axis of the
+// scale handle being dragged, or along all axes for ScaleHandleUniform, by an
+// amount based on the mouse drag in pixels. If the whole component is being
+// scaled, the mesh offsets are scaled from the component origin too.
+func (g *Gizmo) OnScaleDrag(dx, dy float32, activeAxis int, comp *component.Component) {
+	if activeAxis < 0 || activeAxis >= ScaleHandleCount {
+		return
+	}
+
+	factor := scaleDragToFactor(dx, dy)
+	if factor == 1.0 {
+		return
+	}
+
+	scale := mgl.Vec3{factor, factor, factor}
+	if activeAxis != ScaleHandleUniform {
+		scale = mgl.Vec3{1, 1, 1}
+		scale[activeAxis/2] = factor
+	}
+
+	targets := getTargetMeshes(comp)
+	scaleOffsets := len(targets) > 1 || activeMesh == nil
+	for _, compMesh := range targets {
+		scaleMesh(compMesh, scale, scaleOffsets)
+	}
+}
+
+// scaleMesh multiplies the scale of the mesh by the scale vector. A zero scale
+// is unset and is treated as {1, 1, 1}. If scaleOffset is true, the mesh offset
+// is scaled from the component origin as well.
+func scaleMesh(compMesh *component.Mesh, scale mgl.Vec3, scaleOffset bool) {
+	if compMesh.Scale == (mgl.Vec3{}) {
+		compMesh.Scale = mgl.Vec3{1, 1, 1}
+	}
+	for i := 0; i < 3; i++ {
+		compMesh.Scale[i] *= scale[i]
+		if scaleOffset {
+			compMesh.Offset[i] *= scale[i]
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package main
+
+import (
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+
+	component "github.com/tbogdala/fizzle/component"
+)
+
+func TestRotateDragToDegrees(t *testing.T) {
+	tests := []struct {
+		dx, dy   float32
+		expected float32
+	}{
+		{0, 0, 0},
+		{10, 0, 10 * gizmoRotateDegreesPerPixel},
+		{0, -10, 10 * gizmoRotateDegreesPerPixel},
+		{-10, 0, -10 * gizmoRotateDegreesPerPixel},
+		{0, 10, -10 * gizmoRotateDegreesPerPixel},
+		{10, 10, 0},
+	}
+
+	for _, test := range tests {
+		degrees := rotateDragToDegrees(test.dx, test.dy)
+		if degrees != test.expected {
+			t.Errorf("rotateDragToDegrees(%f, %f) returned %f; expected %f", test.dx, test.dy, degrees, test.expected)
+		}
+	}
+}
+
+func TestScaleDragToFactor(t *testing.T) {
+	tests := []struct {
+		dx, dy   float32
+		expected float32
+	}{
+		{0, 0, 1.0},
+		{10, 0, 1.0 + 10*gizmoScalePerPixel},
+		{0, -10, 1.0 + 10*gizmoScalePerPixel},
+		{-10, 0, 1.0 - 10*gizmoScalePerPixel},
+		{-100000, 0, gizmoMinScaleFactor},
+		{0, 100000, gizmoMinScaleFactor},
+	}
+
+	for _, test := range tests {
+		factor := scaleDragToFactor(test.dx, test.dy)
+		if factor != test.expected {
+			t.Errorf("scaleDragToFactor(%f, %f) returned %f; expected %f", test.dx, test.dy, factor, test.expected)
+		}
+	}
+}
+
+func TestGizmoEndDragPushesChanges(t *testing.T) {
+	editHistory = newUndoHistory()
+	activeMesh = nil
+
+	comp := new(component.Component)
+	compMesh := new(component.Mesh)
+	compMesh.Scale = mgl.Vec3{1, 1, 1}
+	comp.Meshes = []*component.Mesh{compMesh}
+
+	g := new(Gizmo)
+	g.ActiveAxis = -1
+	g.Mode = TransformRotate
+
+	// clicking a handle without moving the mouse changes nothing
+	g.BeginDrag(1, comp)
+	g.EndDrag()
+	if editHistory.Top() != nil {
+		t.Errorf("A drag that didn't change the meshes was pushed to the undo history.")
+	}
+	if g.IsDragging() {
+		t.Errorf("The gizmo is still dragging after EndDrag.")
+	}
+
+	g.BeginDrag(1, comp)
+	g.OnDrag(20, 0, comp)
+	g.EndDrag()
+	if editHistory.Top() == nil {
+		t.Fatalf("A drag that rotated the mesh wasn't pushed to the undo history.")
+	}
+
+	editHistory.Undo()
+	if compMesh.RotationDegrees != 0.0 {
+		t.Errorf("Undoing the drag left the mesh rotated %f degrees.", compMesh.RotationDegrees)
+	}
+}
+
+func TestGizmoOnScaleDragAxes(t *testing.T) {
+	factor := scaleDragToFactor(20, 0)
+	tests := []struct {
+		handle   int
+		scale    mgl.Vec3
+		expected mgl.Vec3
+	}{
+		{0, mgl.Vec3{1, 2, 3}, mgl.Vec3{factor, 2, 3}},
+		{1, mgl.Vec3{1, 2, 3}, mgl.Vec3{factor, 2, 3}},
+		{2, mgl.Vec3{1, 2, 3}, mgl.Vec3{1, 2 * factor, 3}},
+		{5, mgl.Vec3{1, 2, 3}, mgl.Vec3{1, 2, 3 * factor}},
+		{ScaleHandleUniform, mgl.Vec3{1, 2, 3}, mgl.Vec3{factor, 2 * factor, 3 * factor}},
+		// an unset scale is the same as {1, 1, 1}
+		{2, mgl.Vec3{0, 0, 0}, mgl.Vec3{1, factor, 1}},
+		{ScaleHandleUniform, mgl.Vec3{0, 0, 0}, mgl.Vec3{factor, factor, factor}},
+	}
+
+	for _, test := range tests {
+		comp := new(component.Component)
+		compMesh := new(component.Mesh)
+		compMesh.Scale = test.scale
+		compMesh.Offset = mgl.Vec3{1, 1, 1}
+		comp.Meshes = []*component.Mesh{compMesh}
+		activeMesh = compMesh
+
+		g := new(Gizmo)
+		g.OnScaleDrag(20, 0, test.handle, comp)
+		if !compMesh.Scale.ApproxEqualThreshold(test.expected, 1e-5) {
+			t.Errorf("Scale handle %d changed scale %v to %v; expected %v", test.handle, test.scale, compMesh.Scale, test.expected)
+		}
+		if compMesh.Offset != (mgl.Vec3{1, 1, 1}) {
+			t.Errorf("Scale handle %d moved the active mesh offset to %v", test.handle, compMesh.Offset)
+		}
+	}
+	activeMesh = nil
+}
@@ -33,8 +33,6 @@ import (
 var (
 	windowWidth  = 1280
 	windowHeight = 720
-	perspNear    = float32(0.1)
-	perspFar     = float32(100.0)
 	mainWindow   *glfw.Window
 	camera       *fizzle.OrbitCamera
 	uiman        *gui.Manager
@@ -54,6 +52,60 @@ var (
 	// childRefFilenames is a map of child reference filename to component name
 	childRefFilenames map[string]string
 
+	// editorPrefs are the editor settings saved between runs
+	editorPrefs EditorPrefs
+
+	// editHistory is the undo history for edits made to the component
+	editHistory *undoHistory
+
+	// cameraHistory is the undo history for camera movement. It is kept apart
+	// from the editHistory on purpose: pushing a camera move onto the edit
+	// history would clear any edits that could be redone every time the view
+	// is orbited, and Undo would step through views before reaching the edit
+	// the user wanted to undo. The Undo and Redo buttons only ever change the
+	// component while Alt+Left and Alt+Right only ever change the camera.
+	cameraHistory *undoHistory
+
+	// cameraMoves pushes camera movement onto the cameraHistory
+	cameraMoves *cameraMoveTracker
+
+	// activeMesh is the mesh selected for editing, if any
+	activeMesh *component.Mesh
+
+	// gizmo is the transform gizmo drawn for the component
+	gizmo *Gizmo
+
+	// outlineShader draws the edge highlight around the active mesh using
+	// outlineColor and outlineThickness.
+	outlineShader    *fizzle.RenderShader
+	outlineColor     = mgl.Vec4{1.0, 0.6, 0.0, 1.0}
+	outlineThickness = float32(0.02)
+
+	// frameTimeSparkline and drawCallSparkline plot the frame time in
+	// milliseconds and the number of draw calls for recent frames.
+	frameTimeSparkline *Sparkline
+	drawCallSparkline  *Sparkline
+
+	// materialPreview shows the material of the active mesh on a sphere.
+	materialPreview *MaterialPreview
+
+	// lastCursorX and lastCursorY are the mouse position from the last frame
+	// and lmbWasPressed is the left mouse button state from the last frame.
+	lastCursorX   float64
+	lastCursorY   float64
+	lmbWasPressed bool
+
+	// cameraUndoWasPressed and cameraRedoWasPressed are the states of the
+	// camera history key bindings from the last frame.
+	cameraUndoWasPressed bool
+	cameraRedoWasPressed bool
+
+	// onAnimationEvent is called when playback of a mesh animation crosses
+	// the frame of one of the component's animation events.
+	onAnimationEvent component.AnimationEventHandler = func(comp *component.Component, event component.AnimationEvent) {
+		groggy.Logsf("DEBUG", "Animation event \"%s\" triggered for %s at frame %d of %s.", event.EventName, comp.Name, event.Frame, event.ClipName)
+	}
+
 	appStartTime time.Time
 	totalTime    float64
 )
@@ -91,6 +143,10 @@ type meshRenderable struct {
 	ComponentMesh     *component.Mesh
 	Renderable        *fizzle.Renderable
 	AnimationsEnabled []bool
+
+	// AnimationFrames tracks the last frame played for each animation so that
+	// animation events can be fired when a frame is crossed; -1 means stopped.
+	AnimationFrames []int
 }
 
 // colliderRenderable is used to tie together state for the component collider
@@ -136,6 +192,48 @@ func guiAddSliderVec4(wnd *gui.Window, widthS float32, idPrefix string, index in
 	wnd.SliderFloat(fmt.Sprintf("%s%d_3", idPrefix, index), &v[3], min, max)
 }
 
+// guiAddBoundEditbox adds an editbox for a string property binding.
+func guiAddBoundEditbox(wnd *gui.Window, id string, b *PropertyBinding[string]) {
+	v := b.Get()
+	wnd.Editbox(id, &v)
+	b.Set(v)
+}
+
+// guiAddBoundCheckbox adds a checkbox for a bool property binding.
+func guiAddBoundCheckbox(wnd *gui.Window, id string, b *PropertyBinding[bool]) {
+	v := b.Get()
+	wnd.Checkbox(id, &v)
+	b.Set(v)
+}
+
+// guiAddBoundDragSliderFloat adds a drag slider float for a float32 property binding.
+func guiAddBoundDragSliderFloat(wnd *gui.Window, id string, speed float32, b *PropertyBinding[float32]) {
+	v := b.Get()
+	wnd.DragSliderFloat(id, speed, &v)
+	b.Set(v)
+}
+
+// guiAddBoundDragSliderUFloat adds an unsigned drag slider float for a float32 property binding.
+func guiAddBoundDragSliderUFloat(wnd *gui.Window, id string, speed float32, b *PropertyBinding[float32]) {
+	v := b.Get()
+	wnd.DragSliderUFloat(id, speed, &v)
+	b.Set(v)
+}
+
+// guiAddBoundDragSliderVec3 adds drag slider floats for a Vec3 property binding.
+func guiAddBoundDragSliderVec3(wnd *gui.Window, widthS float32, idPrefix string, index int, speed float32, b *PropertyBinding[mgl.Vec3]) {
+	v := b.Get()
+	guiAddDragSliderVec3(wnd, widthS, idPrefix, index, speed, &v)
+	b.Set(v)
+}
+
+// guiAddBoundSliderVec4 adds slider floats for a Vec4 property binding.
+func guiAddBoundSliderVec4(wnd *gui.Window, widthS float32, idPrefix string, index int, b *PropertyBinding[mgl.Vec4], min, max float32) {
+	v := b.Get()
+	guiAddSliderVec4(wnd, widthS, idPrefix, index, &v, min, max)
+	b.Set(v)
+}
+
 // getLoadedChildComponent uses the global childRefFilenames map to look up a
 // component name for a given child reference name and then find that component
 // in the loaded child components slice. Returns nil if no match is found.
@@ -179,21 +277,45 @@ func makeRenderableForMesh(compMesh *component.Mesh) *fizzle.Renderable {
 			if err != nil {
 				fmt.Printf("Failed to decode Gombz mesh from %s: %v\n", gombzFilepath, err)
 			} else {
+				recordEditorFile(component.AssetTypeMesh, gombzFilepath)
 				fmt.Printf("Loaded gombz mesh: %s\n", compMesh.SrcFile)
 			}
 		}
 	}
 
+	if compMesh.SrcMesh != nil && compMesh.AutoCenter {
+		compMesh.CenterOffset = component.CenterMesh(compMesh.SrcMesh)
+	}
+
+	// generate texture coordinates for meshes that don't have them
+	if compMesh.SrcMesh != nil && (len(compMesh.SrcMesh.UVChannels) == 0 || len(compMesh.SrcMesh.UVChannels[0]) == 0) {
+		err := compMesh.AutoUnwrapUV(compMesh.UVUnwrap)
+		if err != nil {
+			fmt.Printf("Failed to generate texture coordinates for %s: %v\n", compMesh.Name, err)
+		}
+	}
+
+	return createMeshRenderable(compMesh)
+}
+
+// createMeshRenderable creates the renderable for the mesh data already loaded
+// into the component mesh and stores it in visibleMeshes, destroying the
+// renderable it replaces if one existed. Returns nil if no mesh data is loaded.
+func createMeshRenderable(compMesh *component.Mesh) *fizzle.Renderable {
 	// if we haven't loaded something by now, then return a nil renderable
 	if compMesh.SrcMesh == nil {
 		return nil
 	}
 
+	if oldRenderable, okay := visibleMeshes[compMesh.Name]; okay && oldRenderable.Renderable != nil {
+		oldRenderable.Renderable.Destroy()
+	}
+
 	compRenderable := new(meshRenderable)
 	r := fizzle.CreateFromGombz(compMesh.SrcMesh)
 	r.Material = fizzle.NewMaterial()
 	r.Material.Shader = shaders["BasicSkinned"]
-	r.Location = compMesh.Offset
+	r.Location = compMesh.Offset.Add(compMesh.CenterOffset)
 	r.Scale = compMesh.Scale
 
 	// Create a quaternion if rotation parameters are set
@@ -207,8 +329,10 @@ func makeRenderableForMesh(compMesh *component.Mesh) *fizzle.Renderable {
 
 	// setup the animation enable flag slice
 	compRenderable.AnimationsEnabled = []bool{}
+	compRenderable.AnimationFrames = []int{}
 	for i := 0; i < len(compMesh.SrcMesh.Animations); i++ {
 		compRenderable.AnimationsEnabled = append(compRenderable.AnimationsEnabled, false)
+		compRenderable.AnimationFrames = append(compRenderable.AnimationFrames, -1)
 	}
 
 	visibleMeshes[compMesh.Name] = compRenderable
@@ -231,9 +355,17 @@ func doSaveGombz(compMesh *component.Mesh) error {
 	}
 
 	fmt.Printf("Wrote Gombz file: %s\n", gombzFilepath)
+	recordEditorFile(component.AssetTypeMesh, gombzFilepath)
 	return nil
 }
 
+// recordEditorFile records a file loaded or saved for the component being
+// edited with the component manager so that it shows up in the asset watch
+// window. Files are recorded under the component file path.
+func recordEditorFile(assetType string, path string) {
+	componentMan.RecordLoadedFile(assetType, flagComponentFile, path)
+}
+
 // doLoadTexture loads a relative filepath texture into the
 // texture manager.
 func doLoadTexture(texFile string) error {
@@ -243,8 +375,12 @@ func doLoadTexture(texFile string) error {
 	if err != nil {
 		return fmt.Errorf("Failed to load texture %s: %v", texFile, err)
 	}
+	componentMan.RecordLoadedFile(component.AssetTypeTexture, texFile, texFilepath)
 
 	fmt.Printf("Loaded texture: %s\n", texFile)
+	if materialPreview != nil {
+		materialPreview.MarkDirty()
+	}
 	return nil
 }
 
@@ -259,9 +395,12 @@ func doDeleteTexture(texIndex int, matTextures []string) []string {
 	return append(matTextures[:texIndex], matTextures[texIndex+1:]...)
 }
 
-func doAnimation(animation *gombz.Animation, renderable *fizzle.Renderable, totalTime float64) {
+// doAnimation animates the renderable's skeleton and returns the animation frame
+// that was posed.
+func doAnimation(animation *gombz.Animation, renderable *fizzle.Renderable, totalTime float64) int {
 	aniTime := float32(math.Mod(totalTime*float64(animation.TicksPerSecond), float64(animation.Duration)))
 	renderable.Core.Skeleton.Animate(animation, aniTime)
+	return int(aniTime)
 }
 
 // getComponentPrefix gets the prefix directory for the current component filename.
@@ -290,14 +429,26 @@ func loadAllReferenceTextures(compMesh *component.Mesh) {
 }
 
 func doLoadComponentFile(componentFilepath string) {
-	existingCompJSON, err := ioutil.ReadFile(componentFilepath)
+	existingCompBytes, err := ioutil.ReadFile(componentFilepath)
 	if err == nil {
-		err := json.Unmarshal(existingCompJSON, &theComponent)
+		if isTOMLComponentFile(componentFilepath) {
+			err = component.DecodeComponentTOML(existingCompBytes, &theComponent)
+		} else {
+			err = json.Unmarshal(existingCompBytes, &theComponent)
+		}
 		if err != nil {
 			fmt.Printf("Failed to load component %s: %v\n", componentFilepath, err)
 		} else {
 			fmt.Printf("Loaded component: %s\n", componentFilepath)
 
+			// watch the files of the new component instead of the old one
+			componentMan.ForgetLoadedComponentFiles(flagComponentFile)
+			recordEditorFile(component.AssetTypeComponent, componentFilepath)
+
+			// edits to the previous component can no longer be undone
+			editHistory.Clear()
+			activeMesh = nil
+
 			// destroy all existing renderables
 			for _, r := range visibleMeshes {
 				r.Renderable.Destroy()
@@ -326,8 +477,27 @@ func doLoadComponentFile(componentFilepath string) {
 	}
 }
 
-// doSaveComponent saves the component to a file.
+// isTOMLComponentFile returns true if the component file should be read and
+// written as TOML instead of JSON.
+func isTOMLComponentFile(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), component.TOMLFileExtension)
+}
+
+// doSaveComponent saves the component to a file. The component is saved as TOML
+// if the file has the TOML extension and JSON otherwise.
 func doSaveComponent(comp *component.Component, filepath string) error {
+	if isTOMLComponentFile(filepath) {
+		compTOML, tomlErr := component.EncodeComponentTOML(comp)
+		if tomlErr != nil {
+			return fmt.Errorf("Failed to serialize component to TOML: %v\n", tomlErr)
+		}
+		fileErr := ioutil.WriteFile(filepath, compTOML, 0744)
+		if fileErr != nil {
+			return fmt.Errorf("Failed to write component: %v\n", fileErr)
+		}
+		return nil
+	}
+
 	compJSON, jsonErr := json.MarshalIndent(comp, "", "    ")
 	if jsonErr == nil {
 		fileErr := ioutil.WriteFile(filepath, compJSON, 0744)
@@ -364,6 +534,40 @@ func doAddMesh() {
 	createMeshWindow(newCompMesh, meshWndX, meshWndY)
 }
 
+// doAddPrimitive creates a built-in primitive component, registers it with the
+// component manager under the shape's name and then adds a copy of its mesh
+// to the component being edited. The mesh data is written to a gombz file
+// named after the new mesh so that the saved component can load it.
+func doAddPrimitive(shape component.PrimitiveShape) error {
+	primComp, err := component.CreatePrimitive(shape, component.DefaultPrimitiveParams())
+	if err != nil {
+		return fmt.Errorf("Failed to create the %s primitive: %v", shape, err)
+	}
+	componentMan.AddComponent(shape.String(), primComp)
+
+	// copy the mesh data so that edits don't change the registered primitive
+	newCompMesh := component.NewMesh()
+	newCompMesh.Name = fmt.Sprintf("%s %d", shape, len(theComponent.Meshes)+1)
+	newCompMesh.BinFile = strings.ToLower(strings.Replace(newCompMesh.Name, " ", "_", -1)) + ".gombz"
+	newCompMesh.SrcMesh = component.CopyMesh(primComp.Meshes[0].SrcMesh)
+	err = doSaveGombz(newCompMesh)
+	if err != nil {
+		return fmt.Errorf("Failed to save the %s primitive: %v", shape, err)
+	}
+
+	theComponent.Meshes = append(theComponent.Meshes, newCompMesh)
+	createMeshWindow(newCompMesh, meshWndX, meshWndY)
+	createMeshRenderable(newCompMesh)
+	return nil
+}
+
+// doSetMeshData replaces the source mesh data for the component mesh as
+// an undoable edit.
+func doSetMeshData(compMesh *component.Mesh, newMesh *gombz.Mesh) {
+	cmd := &meshDataCommand{compMesh: compMesh, oldMesh: compMesh.SrcMesh, newMesh: newMesh}
+	editHistory.Execute(cmd)
+}
+
 // doDeleteMesh destroys the renderable for a component mesh and then
 // removes the mesh from the map of visibleMeshes.
 func doDeleteMesh(componentMeshName string) {
@@ -524,17 +728,172 @@ func removeStaleChildComponents(childComps []*component.Component, parentComp *c
 
 var (
 	meshWindowCount = 0
+
+	// showBuiltinLibrary is true when the built-in primitive list is expanded
+	showBuiltinLibrary = false
 )
 
+// renderTextureSettings does the user interface for the mipmap settings of each
+// texture of the mesh material that has been loaded. Changes are applied to the
+// texture immediately but are not saved with the component.
+func renderTextureSettings(wnd *gui.Window, compMesh *component.Mesh, wndCount int) {
+	textureNames := []string{compMesh.Material.DiffuseTexture, compMesh.Material.NormalsTexture, compMesh.Material.SpecularTexture}
+	textureNames = append(textureNames, compMesh.Material.Textures...)
+
+	shownHeader := false
+	for texIndex, texName := range textureNames {
+		settings, texFound := textureMan.GetMipSettings(texName)
+		if !texFound {
+			continue
+		}
+		if !shownHeader {
+			wnd.Separator()
+			wnd.RequestItemWidthMin(textWidth)
+			wnd.Text("Texture Settings")
+			shownHeader = true
+		}
+
+		wnd.StartRow()
+		wnd.RequestItemWidthMin(textWidth)
+		wnd.Text(texName)
+
+		wnd.StartRow()
+		wnd.RequestItemWidthMin(textWidth)
+		wnd.Text("Mip Bias")
+		mipBias := settings.MipBias
+		wnd.SliderFloat(fmt.Sprintf("textureMipBias%d_%d", texIndex, wndCount), &mipBias, -4.0, 4.0)
+		if mipBias != settings.MipBias {
+			textureMan.SetMipBias(texName, mipBias)
+		}
+
+		wnd.StartRow()
+		wnd.RequestItemWidthMin(textWidth)
+		wnd.Text("Mip Levels")
+		minLevel, maxLevel := settings.MinMipLevel, settings.MaxMipLevel
+		minDown, _ := wnd.Button(fmt.Sprintf("textureMinMipDown%d_%d", texIndex, wndCount), "-")
+		wnd.Text(fmt.Sprintf("%d", minLevel))
+		minUp, _ := wnd.Button(fmt.Sprintf("textureMinMipUp%d_%d", texIndex, wndCount), "+")
+		maxDown, _ := wnd.Button(fmt.Sprintf("textureMaxMipDown%d_%d", texIndex, wndCount), "-")
+		wnd.Text(fmt.Sprintf("%d", maxLevel))
+		maxUp, _ := wnd.Button(fmt.Sprintf("textureMaxMipUp%d_%d", texIndex, wndCount), "+")
+		if minDown && minLevel > 0 {
+			minLevel--
+		}
+		// the default max level is far beyond any real texture so the levels
+		// are limited to the ones the texture's size has
+		levelCount, _ := textureMan.GetMipLevelCount(texName)
+		if minUp && minLevel < maxLevel && minLevel < levelCount-1 {
+			minLevel++
+		}
+		if maxDown {
+			if maxLevel > levelCount-1 {
+				maxLevel = levelCount - 1
+			}
+			if maxLevel > minLevel {
+				maxLevel--
+			}
+		}
+		if maxUp && maxLevel < levelCount-1 {
+			maxLevel++
+		}
+		if minLevel != settings.MinMipLevel || maxLevel != settings.MaxMipLevel {
+			textureMan.SetMinMaxMipLevel(texName, minLevel, maxLevel)
+		}
+	}
+}
+
+// renderBuiltinLibrary adds the collapsible list of built-in primitive shapes
+// to the window that can be added to the component.
+func renderBuiltinLibrary(wnd *gui.Window) {
+	wnd.Separator()
+	wnd.RequestItemWidthMin(textWidth)
+	wnd.Text("Library:")
+	toggleText := "+"
+	if showBuiltinLibrary {
+		toggleText = "-"
+	}
+	toggleLibrary, _ := wnd.Button("buttonToggleBuiltinLibrary", toggleText)
+	if toggleLibrary {
+		showBuiltinLibrary = !showBuiltinLibrary
+	}
+	if !showBuiltinLibrary {
+		return
+	}
+
+	for shape := component.PrimitiveShape(0); shape < component.PrimitiveShapeCount; shape++ {
+		wnd.StartRow()
+		wnd.Space(textWidth)
+		addPrimitive, _ := wnd.Button(fmt.Sprintf("buttonAddPrimitive%d", shape), shape.String())
+		if addPrimitive {
+			err := doAddPrimitive(shape)
+			if err != nil {
+				fmt.Printf("%v\n", err)
+			}
+		}
+	}
+}
+
 func createMeshWindow(newCompMesh *component.Mesh, screenX, screenY float32) {
 	meshWindowCount++
 	wndCount := meshWindowCount
+
+	// bind the mesh properties to the undo history
+	nameBinding := NewPropertyBinding(editHistory,
+		func() string { return newCompMesh.Name },
+		func(v string) { newCompMesh.Name = v })
+	offsetBinding := NewPropertyBinding(editHistory,
+		func() mgl.Vec3 { return newCompMesh.Offset },
+		func(v mgl.Vec3) { newCompMesh.Offset = v })
+	scaleBinding := NewPropertyBinding(editHistory,
+		func() mgl.Vec3 { return newCompMesh.Scale },
+		func(v mgl.Vec3) { newCompMesh.Scale = v })
+	rotAxisBinding := NewPropertyBinding(editHistory,
+		func() mgl.Vec3 { return newCompMesh.RotationAxis },
+		func(v mgl.Vec3) { newCompMesh.RotationAxis = v })
+	rotDegreesBinding := NewPropertyBinding(editHistory,
+		func() float32 { return newCompMesh.RotationDegrees },
+		func(v float32) { newCompMesh.RotationDegrees = v })
+	autoCenterBinding := NewPropertyBinding(editHistory,
+		func() bool { return newCompMesh.AutoCenter },
+		func(v bool) { newCompMesh.AutoCenter = v })
+	shaderBinding := NewPropertyBinding(editHistory,
+		func() string { return newCompMesh.Material.ShaderName },
+		func(v string) { newCompMesh.Material.ShaderName = v })
+	diffuseBinding := NewPropertyBinding(editHistory,
+		func() mgl.Vec4 { return newCompMesh.Material.Diffuse },
+		func(v mgl.Vec4) { newCompMesh.Material.Diffuse = v })
+	specularBinding := NewPropertyBinding(editHistory,
+		func() mgl.Vec4 { return newCompMesh.Material.Specular },
+		func(v mgl.Vec4) { newCompMesh.Material.Specular = v })
+	shininessBinding := NewPropertyBinding(editHistory,
+		func() float32 { return newCompMesh.Material.Shininess },
+		func(v float32) { newCompMesh.Material.Shininess = v })
+	diffuseTexBinding := NewPropertyBinding(editHistory,
+		func() string { return newCompMesh.Material.DiffuseTexture },
+		func(v string) { newCompMesh.Material.DiffuseTexture = v })
+	normalsTexBinding := NewPropertyBinding(editHistory,
+		func() string { return newCompMesh.Material.NormalsTexture },
+		func(v string) { newCompMesh.Material.NormalsTexture = v })
+	specularTexBinding := NewPropertyBinding(editHistory,
+		func() string { return newCompMesh.Material.SpecularTexture },
+		func(v string) { newCompMesh.Material.SpecularTexture = v })
+	genMipsBinding := NewPropertyBinding(editHistory,
+		func() bool { return newCompMesh.Material.GenerateMipmaps },
+		func(v bool) { newCompMesh.Material.GenerateMipmaps = v })
+
+	// settings for the mesh operations
+	smoothIterations := 1
+	smoothFactor := float32(0.5)
+	displaceScale := float32(1.0)
+	displaceAmplitude := float32(0.1)
+	displaceSeed := float32(0.0)
+
 	// FIXME: find a better spot to spawn potentially
 	meshWnd := uiman.NewWindow(compMeshWindowID, screenX, screenY, 0.30, 0.75, func(wnd *gui.Window) {
 		compRenderable := visibleMeshes[newCompMesh.Name]
 		wnd.RequestItemWidthMin(textWidth)
 		wnd.Text("Name")
-		wnd.Editbox(fmt.Sprintf("meshNameEditbox%d", wndCount), &newCompMesh.Name)
+		guiAddBoundEditbox(wnd, fmt.Sprintf("meshNameEditbox%d", wndCount), nameBinding)
 
 		// force the window id to be the mesh name plus a prefix
 		wnd.ID = fmt.Sprintf("%s%s", compMeshWindowID, newCompMesh.Name)
@@ -560,50 +919,96 @@ func createMeshWindow(newCompMesh *component.Mesh, screenX, screenY float32) {
 		wnd.StartRow()
 		wnd.RequestItemWidthMin(textWidth)
 		wnd.Text("Offset")
-		guiAddDragSliderVec3(wnd, width3Col, "MeshOffset", wndCount, 0.1, &newCompMesh.Offset)
+		guiAddBoundDragSliderVec3(wnd, width3Col, "MeshOffset", wndCount, 0.1, offsetBinding)
 
 		wnd.StartRow()
 		wnd.RequestItemWidthMin(textWidth)
 		wnd.Text("Scale")
-		guiAddDragSliderVec3(wnd, width3Col, "MeshScale", wndCount, 0.1, &newCompMesh.Scale)
+		guiAddBoundDragSliderVec3(wnd, width3Col, "MeshScale", wndCount, 0.1, scaleBinding)
 
 		wnd.StartRow()
 		wnd.RequestItemWidthMin(textWidth)
 		wnd.Text("Rotation Axis")
-		guiAddDragSliderVec3(wnd, width3Col, "MeshRotationAxis", wndCount, 0.01, &newCompMesh.RotationAxis)
+		guiAddBoundDragSliderVec3(wnd, width3Col, "MeshRotationAxis", wndCount, 0.01, rotAxisBinding)
 
 		wnd.StartRow()
 		wnd.RequestItemWidthMin(textWidth)
 		wnd.Text("Rotation Degrees")
-		wnd.DragSliderFloat(fmt.Sprintf("MeshRotationDegrees%d", wndCount), 0.1, &newCompMesh.RotationDegrees)
+		guiAddBoundDragSliderFloat(wnd, fmt.Sprintf("MeshRotationDegrees%d", wndCount), 0.1, rotDegreesBinding)
+
+		// auto centering is applied when the mesh data gets loaded
+		wnd.StartRow()
+		wnd.RequestItemWidthMin(textWidth)
+		wnd.Text("Auto Center")
+		guiAddBoundCheckbox(wnd, fmt.Sprintf("MeshAutoCenter%d", wndCount), autoCenterBinding)
+		if newCompMesh.CenterOffset.Len() > 0.0 {
+			wnd.Text(fmt.Sprintf("(%.2f, %.2f, %.2f)", newCompMesh.CenterOffset[0], newCompMesh.CenterOffset[1], newCompMesh.CenterOffset[2]))
+		}
+
+		// ------------------------------------------------
+		// mesh operations
+		if newCompMesh.SrcMesh != nil {
+			wnd.Separator()
+			wnd.RequestItemWidthMin(textWidth)
+			wnd.Text("Smooth")
+			lessIterations, _ := wnd.Button(fmt.Sprintf("meshSmoothLess%d", wndCount), "-")
+			wnd.Text(fmt.Sprintf("%d", smoothIterations))
+			moreIterations, _ := wnd.Button(fmt.Sprintf("meshSmoothMore%d", wndCount), "+")
+			wnd.RequestItemWidthMax(width4Col)
+			wnd.SliderFloat(fmt.Sprintf("meshSmoothFactor%d", wndCount), &smoothFactor, 0.0, 1.0)
+			doSmooth, _ := wnd.Button(fmt.Sprintf("meshSmoothButton%d", wndCount), "Smooth")
+			if lessIterations && smoothIterations > 1 {
+				smoothIterations--
+			}
+			if moreIterations {
+				smoothIterations++
+			}
+			if doSmooth {
+				doSetMeshData(newCompMesh, component.SmoothMesh(newCompMesh.SrcMesh, smoothIterations, smoothFactor))
+			}
+
+			wnd.StartRow()
+			wnd.RequestItemWidthMin(textWidth)
+			wnd.Text("Displace")
+			wnd.RequestItemWidthMax(width4Col)
+			wnd.SliderFloat(fmt.Sprintf("meshDisplaceScale%d", wndCount), &displaceScale, 0.01, 10.0)
+			wnd.RequestItemWidthMax(width4Col)
+			wnd.SliderFloat(fmt.Sprintf("meshDisplaceAmplitude%d", wndCount), &displaceAmplitude, -2.0, 2.0)
+			wnd.RequestItemWidthMax(width4Col)
+			wnd.SliderFloat(fmt.Sprintf("meshDisplaceSeed%d", wndCount), &displaceSeed, 0.0, 1000.0)
+			doDisplace, _ := wnd.Button(fmt.Sprintf("meshDisplaceButton%d", wndCount), "Apply")
+			if doDisplace {
+				doSetMeshData(newCompMesh, component.DisplaceMesh(newCompMesh.SrcMesh, displaceScale, displaceAmplitude, int64(displaceSeed)))
+			}
+		}
 
 		// ------------------------------------------------
 		// material settings
 		wnd.Separator()
 		wnd.RequestItemWidthMin(textWidth)
 		wnd.Text("Shader")
-		wnd.Editbox(fmt.Sprintf("materialShaderNameEditbox%d", wndCount), &newCompMesh.Material.ShaderName)
+		guiAddBoundEditbox(wnd, fmt.Sprintf("materialShaderNameEditbox%d", wndCount), shaderBinding)
 
 		wnd.StartRow()
 		wnd.RequestItemWidthMin(textWidth)
 		wnd.Text("Diffuse")
-		guiAddSliderVec4(wnd, width4Col, "MaterialDiffuse", wndCount, &newCompMesh.Material.Diffuse, 0.0, 1.0)
+		guiAddBoundSliderVec4(wnd, width4Col, "MaterialDiffuse", wndCount, diffuseBinding, 0.0, 1.0)
 
 		wnd.StartRow()
 		wnd.RequestItemWidthMin(textWidth)
 		wnd.Text("Specular")
-		guiAddSliderVec4(wnd, width4Col, "MaterialSpecular", wndCount, &newCompMesh.Material.Specular, 0.0, 1.0)
+		guiAddBoundSliderVec4(wnd, width4Col, "MaterialSpecular", wndCount, specularBinding, 0.0, 1.0)
 
 		wnd.StartRow()
 		wnd.RequestItemWidthMin(textWidth)
 		wnd.Text("Shininess")
-		wnd.DragSliderUFloat(fmt.Sprintf("MaterialShininess%d", wndCount), 0.1, &newCompMesh.Material.Shininess)
+		guiAddBoundDragSliderUFloat(wnd, fmt.Sprintf("MaterialShininess%d", wndCount), 0.1, shininessBinding)
 
 		wnd.StartRow()
 		wnd.RequestItemWidthMin(textWidth)
 		wnd.Text("DiffuseTex")
 		loadDiffuseTexture, _ := wnd.Button(fmt.Sprintf("materialDiffuseTexLoad%d", wndCount), "L")
-		wnd.Editbox(fmt.Sprintf("materialDiffuseTexEditbox%d", wndCount), &newCompMesh.Material.DiffuseTexture)
+		guiAddBoundEditbox(wnd, fmt.Sprintf("materialDiffuseTexEditbox%d", wndCount), diffuseTexBinding)
 		if loadDiffuseTexture {
 			doLoadTexture(newCompMesh.Material.DiffuseTexture)
 		}
@@ -612,7 +1017,7 @@ func createMeshWindow(newCompMesh *component.Mesh, screenX, screenY float32) {
 		wnd.RequestItemWidthMin(textWidth)
 		wnd.Text("NormalsTex")
 		loadNormalsTexture, _ := wnd.Button(fmt.Sprintf("materialNormalsTexLoad%d", wndCount), "L")
-		wnd.Editbox(fmt.Sprintf("materialNormalsTexEditbox%d", wndCount), &newCompMesh.Material.NormalsTexture)
+		guiAddBoundEditbox(wnd, fmt.Sprintf("materialNormalsTexEditbox%d", wndCount), normalsTexBinding)
 		if loadNormalsTexture {
 			doLoadTexture(newCompMesh.Material.NormalsTexture)
 		}
@@ -621,7 +1026,7 @@ func createMeshWindow(newCompMesh *component.Mesh, screenX, screenY float32) {
 		wnd.RequestItemWidthMin(textWidth)
 		wnd.Text("SpecularTex")
 		loadSpecularTexture, _ := wnd.Button(fmt.Sprintf("materialSpecularTexLoad%d", wndCount), "L")
-		wnd.Editbox(fmt.Sprintf("materialSpecularTexEditbox%d", wndCount), &newCompMesh.Material.SpecularTexture)
+		guiAddBoundEditbox(wnd, fmt.Sprintf("materialSpecularTexEditbox%d", wndCount), specularTexBinding)
 		if loadSpecularTexture {
 			doLoadTexture(newCompMesh.Material.SpecularTexture)
 		}
@@ -658,9 +1063,14 @@ func createMeshWindow(newCompMesh *component.Mesh, screenX, screenY float32) {
 
 		wnd.StartRow()
 		wnd.Space(textWidth)
-		wnd.Checkbox(fmt.Sprintf("MaterialGenerateMips%d", wndCount), &newCompMesh.Material.GenerateMipmaps)
+		guiAddBoundCheckbox(wnd, fmt.Sprintf("MaterialGenerateMips%d", wndCount), genMipsBinding)
 		wnd.Text("Generate Mipmaps")
 
+		guiAddMaterialPreview(wnd, newCompMesh)
+
+		// do the user interface for the mipmap settings of the loaded textures
+		renderTextureSettings(wnd, newCompMesh, wndCount)
+
 		// do the user interface for animations
 		if newCompMesh.SrcMesh != nil && compRenderable != nil && len(newCompMesh.SrcMesh.Animations) > 0 {
 			for aniIndex, animation := range newCompMesh.SrcMesh.Animations {
@@ -675,7 +1085,12 @@ func createMeshWindow(newCompMesh *component.Mesh, screenX, screenY float32) {
 				wnd.Checkbox(fmt.Sprintf("RunAnimations %d %d", aniIndex, wndCount), &compRenderable.AnimationsEnabled[0])
 				wnd.Text(animation.Name)
 				if compRenderable.AnimationsEnabled[0] {
-					doAnimation(&animation, compRenderable.Renderable, totalTime)
+					aniFrame := doAnimation(&animation, compRenderable.Renderable, totalTime)
+					aniLength := int(math.Ceil(float64(animation.Duration)))
+					theComponent.FireAnimationEvents(animation.Name, compRenderable.AnimationFrames[aniIndex], aniFrame, aniLength, onAnimationEvent)
+					compRenderable.AnimationFrames[aniIndex] = aniFrame
+				} else {
+					compRenderable.AnimationFrames[aniIndex] = -1
 				}
 			}
 
@@ -690,17 +1105,39 @@ func createMeshWindow(newCompMesh *component.Mesh, screenX, screenY float32) {
 
 // createComponentWindow creates the main component window GUI.
 func createComponentWindow(sX, sY, sW, sH float32) *gui.Window {
+	// bind the component properties to the undo history
+	compNameBinding := NewPropertyBinding(editHistory,
+		func() string { return theComponent.Name },
+		func(v string) { theComponent.Name = v })
+
 	// create a window for operating on the component file
 	componentWindow := uiman.NewWindow("Component", sX, sY, sW, sH, func(wnd *gui.Window) {
 		loadComponent, _ := wnd.Button("componentFileLoadButton", "Load")
 		saveComponent, _ := wnd.Button("componentFileSaveButton", "Save")
+		undoEdit, _ := wnd.Button("componentUndoButton", "Undo")
+		redoEdit, _ := wnd.Button("componentRedoButton", "Redo")
+		showAssets, _ := wnd.Button("componentAssetsButton", "Assets")
+		showVertices, _ := wnd.Button("componentVerticesButton", "Vertices")
 		wnd.Editbox("componentFileEditbox", &flagComponentFile)
+		if undoEdit {
+			editHistory.Undo()
+		}
+		if redoEdit {
+			editHistory.Redo()
+		}
+		if showAssets {
+			doToggleAssetWatchWindow()
+		}
+		if showVertices {
+			doToggleVertexDataWindow()
+		}
 		if saveComponent {
 			err := doSaveComponent(&theComponent, flagComponentFile)
 			if err != nil {
 				fmt.Printf("Failed to save the component.\n%v\n", err)
 			} else {
 				fmt.Printf("Saved the component file: %s\n", flagComponentFile)
+				recordEditorFile(component.AssetTypeComponent, flagComponentFile)
 			}
 		}
 
@@ -714,7 +1151,7 @@ func createComponentWindow(sX, sY, sW, sH float32) *gui.Window {
 		wnd.Separator()
 		wnd.RequestItemWidthMin(textWidth)
 		wnd.Text("Name")
-		wnd.Editbox("componentNameEditbox", &theComponent.Name)
+		guiAddBoundEditbox(wnd, "componentNameEditbox", compNameBinding)
 
 		// do the user interface for mesh windows
 		wnd.Separator()
@@ -730,9 +1167,21 @@ func createComponentWindow(sX, sY, sW, sH float32) *gui.Window {
 			wnd.StartRow()
 			wnd.RequestItemWidthMin(textWidth)
 			wnd.Text(fmt.Sprintf("%s", compMesh.Name))
+			selectText := "Select"
+			if compMesh == activeMesh {
+				selectText = "Deselect"
+			}
+			selectMesh, _ := wnd.Button(fmt.Sprintf("buttonSelectMesh%d", compMeshIndex), selectText)
 			showMeshWnd, _ := wnd.Button(fmt.Sprintf("buttonShowMesh%d", compMeshIndex), "Show")
 			hideMeshWnd, _ := wnd.Button(fmt.Sprintf("buttonHideMesh%d", compMeshIndex), "Hide")
 			deleteMesh, _ := wnd.Button(fmt.Sprintf("buttonDeleteMesh%d", compMeshIndex), "Delete")
+			if selectMesh {
+				if compMesh == activeMesh {
+					activeMesh = nil
+				} else {
+					activeMesh = compMesh
+				}
+			}
 			if showMeshWnd {
 				doShowMeshWindow(compMesh)
 			}
@@ -743,12 +1192,34 @@ func createComponentWindow(sX, sY, sW, sH float32) *gui.Window {
 				meshesThatSurvive = append(meshesThatSurvive, compMesh)
 			} else {
 				doDeleteMesh(compMesh.Name)
+				if compMesh == activeMesh {
+					activeMesh = nil
+				}
 			}
 
 		}
 		// FIXME: not Destroying renderables for meshes that don't survive
 		theComponent.Meshes = meshesThatSurvive
 
+		// do the user interface for adding built-in primitives
+		renderBuiltinLibrary(wnd)
+
+		// do the user interface for the transform gizmo
+		wnd.Separator()
+		wnd.RequestItemWidthMin(textWidth)
+		wnd.Text("Gizmo:")
+		for mode := 0; mode < TransformModeCount; mode++ {
+			setMode, _ := wnd.Button(fmt.Sprintf("buttonGizmoMode%d", mode), gizmoModeNames[mode])
+			if setMode {
+				gizmo.EndDrag()
+				gizmo.Mode = mode
+			}
+		}
+		wnd.Text(gizmo.ModeName())
+
+		// do the user interface for transforming all of the meshes at once
+		renderBulkTransform(wnd)
+
 		// do the user interface for colliders
 		wnd.Separator()
 		wnd.RequestItemWidthMin(textWidth)
@@ -884,51 +1355,56 @@ func createComponentWindow(sX, sY, sW, sH float32) *gui.Window {
 // This also gets attempts to get textures from textureMan as well.
 func updateVisibleMesh(compRenderable *meshRenderable) {
 	// push all settings from the component to the renderable
-	compRenderable.Renderable.Location = compRenderable.ComponentMesh.Offset
+	compRenderable.Renderable.Location = compRenderable.ComponentMesh.Offset.Add(compRenderable.ComponentMesh.CenterOffset)
 	compRenderable.Renderable.Scale = compRenderable.ComponentMesh.Scale
-	compRenderable.Renderable.Material.DiffuseColor = compRenderable.ComponentMesh.Material.Diffuse
 	if compRenderable.ComponentMesh.RotationDegrees != 0.0 {
 		compRenderable.Renderable.LocalRotation = mgl.QuatRotate(
 			mgl.DegToRad(compRenderable.ComponentMesh.RotationDegrees),
 			compRenderable.ComponentMesh.RotationAxis)
 	}
 
-	compRenderable.Renderable.Material.SpecularColor = compRenderable.ComponentMesh.Material.Specular
-	compRenderable.Renderable.Material.Shininess = compRenderable.ComponentMesh.Material.Shininess
+	applyMaterial(compRenderable.Renderable, &compRenderable.ComponentMesh.Material)
+}
+
+// applyMaterial copies the colors, shader and textures of the component material
+// to the renderable's material.
+func applyMaterial(r *fizzle.Renderable, mat *component.Material) {
+	r.Material.DiffuseColor = mat.Diffuse
+	r.Material.SpecularColor = mat.Specular
+	r.Material.Shininess = mat.Shininess
 
 	// try to find a shader
-	shader, shaderFound := shaders[compRenderable.ComponentMesh.Material.ShaderName]
+	shader, shaderFound := shaders[mat.ShaderName]
 	if shaderFound {
-		compRenderable.Renderable.Material.Shader = shader
+		r.Material.Shader = shader
 	}
 
 	// assign textures
-	textures := compRenderable.ComponentMesh.Material.Textures
+	textures := mat.Textures
 	for i := 0; i < len(textures); i++ {
 		glTex, texFound := textureMan.GetTexture(textures[i])
 		if texFound && i < fizzle.MaxCustomTextures {
-			compRenderable.Renderable.Material.CustomTex[i] = glTex
+			r.Material.CustomTex[i] = glTex
 		}
 	}
-	if len(compRenderable.ComponentMesh.Material.DiffuseTexture) > 0 {
-		glTex, texFound := textureMan.GetTexture(compRenderable.ComponentMesh.Material.DiffuseTexture)
+	if len(mat.DiffuseTexture) > 0 {
+		glTex, texFound := textureMan.GetTexture(mat.DiffuseTexture)
 		if texFound {
-			compRenderable.Renderable.Material.DiffuseTex = glTex
+			r.Material.DiffuseTex = glTex
 		}
 	}
-	if len(compRenderable.ComponentMesh.Material.NormalsTexture) > 0 {
-		glTex, texFound := textureMan.GetTexture(compRenderable.ComponentMesh.Material.NormalsTexture)
+	if len(mat.NormalsTexture) > 0 {
+		glTex, texFound := textureMan.GetTexture(mat.NormalsTexture)
 		if texFound {
-			compRenderable.Renderable.Material.NormalsTex = glTex
+			r.Material.NormalsTex = glTex
 		}
 	}
-	if len(compRenderable.ComponentMesh.Material.SpecularTexture) > 0 {
-		glTex, texFound := textureMan.GetTexture(compRenderable.ComponentMesh.Material.SpecularTexture)
+	if len(mat.SpecularTexture) > 0 {
+		glTex, texFound := textureMan.GetTexture(mat.SpecularTexture)
 		if texFound {
-			compRenderable.Renderable.Material.SpecularTex = glTex
+			r.Material.SpecularTex = glTex
 		}
 	}
-
 }
 
 // updateChildComponentRenderable copies the location, scale and rotation from the
@@ -1000,6 +1476,12 @@ func main() {
 		panic("Failed to compile and link the color shader program! " + err.Error())
 	}
 
+	// load the edge detection shader for highlighting the active mesh
+	outlineShader, err = forward.CreateEdgeDetectionShader()
+	if err != nil {
+		panic("Failed to compile and link the edge detection shader program! " + err.Error())
+	}
+
 	shaders = make(map[string]*fizzle.RenderShader)
 	shaders["Basic"] = basicShader
 	shaders["BasicSkinned"] = basicSkinnedShader
@@ -1012,6 +1494,24 @@ func main() {
 	// setup the component manager
 	componentMan = component.NewManager(textureMan, shaders)
 
+	// setup the transform gizmo
+	gizmo, err = NewGizmo(colorShader)
+	if err != nil {
+		panic("Failed to create the transform gizmo! " + err.Error())
+	}
+
+	// setup the performance graphs
+	frameTimeSparkline = NewSparkline(mgl.Vec4{0.2, 1.0, 0.2, 1.0})
+	drawCallSparkline = NewSparkline(mgl.Vec4{1.0, 0.6, 0.2, 1.0})
+
+	// setup the material preview and give its texture to the user interface
+	materialPreview, err = NewMaterialPreview(gfx)
+	if err != nil {
+		fmt.Printf("Material previews are disabled: %v\n", err)
+	} else {
+		materialPreview.textureIndex = uiman.AddTextureToStack(materialPreview.Texture)
+	}
+
 	// setup the camera to look at the component
 	camera = fizzle.NewOrbitCamera(mgl.Vec3{0, 0, 0}, math.Pi/2.0, 5.0, math.Pi/2.0)
 
@@ -1027,6 +1527,15 @@ func main() {
 	visibleMeshes = make(map[string]*meshRenderable)
 	visibleColliders = make([]*colliderRenderable, 0)
 	childRefFilenames = make(map[string]string)
+	editHistory = newUndoHistory()
+	cameraHistory = newUndoHistory()
+	cameraMoves = newCameraMoveTracker(cameraHistory)
+
+	// load the editor preferences saved from the last run
+	editorPrefs, err = loadEditorPrefs(editorPrefsFilename)
+	if err != nil {
+		fmt.Printf("%v", err)
+	}
 
 	// if the component file passed in as a flag exists, try to load it
 	doLoadComponentFile(flagComponentFile)
@@ -1039,6 +1548,12 @@ func main() {
 	componentWindow.IsScrollable = true
 	componentWindow.IsMoveable = true
 
+	// create the window for the camera settings
+	createRendererSettingsWindow(0.01, 0.48, 0.25, 0.18)
+
+	// create the window for the performance statistics
+	createPerfWindow(0.74, 0.30, 0.25, 0.10)
+
 	/////////////////////////////////////////////////////////////////////////////
 	// loop until something told the mainWindow that it should close
 	// set some OpenGL flags
@@ -1065,15 +1580,26 @@ func main() {
 		gfx.Viewport(0, 0, int32(width), int32(height))
 		gfx.ClearColor(clearColor[0], clearColor[1], clearColor[2], clearColor[3])
 		gfx.Clear(graphics.COLOR_BUFFER_BIT | graphics.DEPTH_BUFFER_BIT)
+		renderer.ResetDrawCallCount()
 
-		perspective := mgl.Perspective(mgl.DegToRad(60.0), float32(width)/float32(height), perspNear, perspFar)
+		perspective := mgl.Perspective(mgl.DegToRad(editorPrefs.VerticalFOV), float32(width)/float32(height), editorPrefs.NearDist, editorPrefs.FarDist)
 		view := camera.GetViewMatrix()
 
+		// move the gizmo to the selection and check to see if it's being dragged
+		gizmo.Location = getGizmoLocation()
+		handleGizmoInput(mainWindow, perspective, view)
+
 		// draw the meshes that are visible
+		uploadEditedVertexData(gfx)
 		for _, compRenderable := range visibleMeshes {
 			// push all settings from the component to the renderable
 			updateVisibleMesh(compRenderable)
 
+			// highlight the edges of the active mesh before drawing it on top
+			if compRenderable.ComponentMesh == activeMesh {
+				renderer.DrawOutline(compRenderable.Renderable, outlineShader, outlineColor, outlineThickness, perspective, view, camera)
+			}
+
 			// draw the thing
 			renderer.DrawRenderable(compRenderable.Renderable, nil, perspective, view, camera)
 		}
@@ -1093,8 +1619,19 @@ func main() {
 		for _, visCollider := range visibleColliders {
 			renderer.DrawLines(visCollider.Renderable, colorShader, nil, perspective, view, camera)
 		}
+		if len(theComponent.Meshes) > 0 {
+			gizmo.Draw(renderer, perspective, view, camera)
+		}
+
+		// plot the stats for the frame below the performance window
+		frameTimeSparkline.Push(float32(frameDelta * 1000.0))
+		drawCallSparkline.Push(float32(renderer.GetDrawCallCount()))
+		drawPerfSparklines(colorShader)
 		gfx.Enable(graphics.DEPTH_TEST)
 
+		// update the material preview before the user interface shows it
+		renderMaterialPreview(gfx)
+
 		// draw the user interface
 		uiman.Construct(frameDelta)
 		uiman.Draw()
@@ -1109,6 +1646,12 @@ func main() {
 		lastFrame = thisFrame
 	}
 
+	// save the editor preferences for the next run
+	err = saveEditorPrefs(editorPrefsFilename, editorPrefs)
+	if err != nil {
+		fmt.Printf("%v", err)
+	}
+
 	// cleanup
 	for _, vc := range visibleColliders {
 		vc.Renderable.Destroy()
@@ -1116,11 +1659,18 @@ func main() {
 	for _, vm := range visibleMeshes {
 		vm.Renderable.Destroy()
 	}
+	gizmo.Destroy()
+	if materialPreview != nil {
+		materialPreview.Destroy(gfx)
+	}
+	frameTimeSparkline.Destroy()
+	drawCallSparkline.Destroy()
 	textureMan.Destroy()
 	componentMan.Destroy()
 	for _, shader := range shaders {
 		shader.Destroy()
 	}
+	outlineShader.Destroy()
 
 	renderer.Destroy()
 }
@@ -1179,8 +1729,29 @@ func handleInput(w *glfw.Window, delta float32) {
 	const zoomSpeed float32 = 3.0
 	const rotSpeed = math.Pi
 
+	// step back and forward through the camera history with Alt+Left and Alt+Right
+	altPressed := w.GetKey(glfw.KeyLeftAlt) == glfw.Press || w.GetKey(glfw.KeyRightAlt) == glfw.Press
+	cameraUndoPressed := altPressed && w.GetKey(glfw.KeyLeft) == glfw.Press
+	cameraRedoPressed := altPressed && w.GetKey(glfw.KeyRight) == glfw.Press
+	if cameraUndoPressed && !cameraUndoWasPressed {
+		cameraMoves.EndDrag()
+		cameraHistory.Undo()
+	}
+	if cameraRedoPressed && !cameraRedoWasPressed {
+		cameraMoves.EndDrag()
+		cameraHistory.Redo()
+	}
+	cameraUndoWasPressed = cameraUndoPressed
+	cameraRedoWasPressed = cameraRedoPressed
+
 	rmbStatus := w.GetMouseButton(glfw.MouseButton2)
-	if rmbStatus == glfw.Press {
+	if rmbStatus != glfw.Press {
+		cameraMoves.EndDrag()
+	} else {
+		// record the camera movement made while dragging so it can be undone
+		cameraBefore := camera.SaveCameraState()
+		defer cameraMoves.Moved(camera, cameraBefore, time.Now())
+
 		if w.GetKey(glfw.KeyA) == glfw.Press {
 			camera.Rotate(delta * rotSpeed)
 		}
@@ -1210,6 +1781,97 @@ func handleInput(w *glfw.Window, delta float32) {
 	}
 }
 
+// createPerfWindow creates the window showing the latest frame statistics.
+// The sparklines for the statistics are drawn underneath it by drawPerfSparklines().
+func createPerfWindow(sX, sY, sW, sH float32) *gui.Window {
+	perfWindow := uiman.NewWindow("Performance", sX, sY, sW, sH, func(wnd *gui.Window) {
+		frameMin, frameMax := frameTimeSparkline.MinMax()
+		wnd.Text(fmt.Sprintf("Frame: %.2f ms (%.2f - %.2f)", frameTimeSparkline.Last(), frameMin, frameMax))
+		wnd.StartRow()
+		drawMin, drawMax := drawCallSparkline.MinMax()
+		wnd.Text(fmt.Sprintf("Draw calls: %d (%d - %d)", int(drawCallSparkline.Last()), int(drawMin), int(drawMax)))
+	})
+	perfWindow.Title = "Performance"
+	perfWindow.ShowTitleBar = false
+	return perfWindow
+}
+
+// drawPerfSparklines draws the frame time and draw call graphs in the bottom
+// right corner of the screen. Each graph is scaled from zero to its largest sample.
+func drawPerfSparklines(colorShader *fizzle.RenderShader) {
+	width, height := renderer.GetResolution()
+	x := float32(width) * 0.74
+	w := float32(width) * 0.25
+	h := float32(height) * 0.08
+
+	_, frameMax := frameTimeSparkline.MinMax()
+	frameTimeSparkline.Draw(renderer, colorShader, camera, x, float32(height)*0.11, w, h, 0.0, frameMax)
+
+	_, drawMax := drawCallSparkline.MinMax()
+	drawCallSparkline.Draw(renderer, colorShader, camera, x, float32(height)*0.02, w, h, 0.0, drawMax)
+}
+
+// getGizmoLocation returns the world-space location for the gizmo which is at the
+// active mesh if one is selected or at the component origin otherwise.
+func getGizmoLocation() mgl.Vec3 {
+	for _, compMesh := range theComponent.Meshes {
+		if compMesh == activeMesh {
+			return theComponent.Location.Add(compMesh.Offset).Add(compMesh.CenterOffset)
+		}
+	}
+	return theComponent.Location
+}
+
+// getMouseRay returns the origin and direction of the ray going from the camera
+// into the scene through the mouse cursor.
+func getMouseRay(w *glfw.Window, perspective mgl.Mat4, view mgl.Mat4) (mgl.Vec3, mgl.Vec3) {
+	width, height := renderer.GetResolution()
+	mx, my := w.GetCursorPos()
+
+	// window coordinates have Y going down the screen
+	winX := float32(mx)
+	winY := float32(height) - float32(my)
+	nearPos, _ := mgl.UnProject(mgl.Vec3{winX, winY, 0.0}, view, perspective, 0, 0, int(width), int(height))
+	farPos, _ := mgl.UnProject(mgl.Vec3{winX, winY, 1.0}, view, perspective, 0, 0, int(width), int(height))
+
+	return nearPos, farPos.Sub(nearPos).Normalize()
+}
+
+// isMouseOverGUI returns true if the mouse cursor is over one of the user
+// interface windows, in which case mouse clicks belong to the window.
+func isMouseOverGUI() bool {
+	mx, my := uiman.GetMousePosition()
+	hovered := uiman.GetWindowsByFilter(func(w *gui.Window) bool {
+		return w.ContainsPosition(mx, my)
+	})
+	return len(hovered) > 0
+}
+
+// handleGizmoInput starts a gizmo drag when the left mouse button is pressed over
+// one of its handles, applies the mouse movement while the button is held and
+// ends the drag when the button is released. Clicks on the user interface
+// windows don't start a drag even if a handle is behind them.
+func handleGizmoInput(w *glfw.Window, perspective mgl.Mat4, view mgl.Mat4) {
+	mx, my := w.GetCursorPos()
+	lmbPressed := w.GetMouseButton(glfw.MouseButton1) == glfw.Press
+
+	if lmbPressed && !lmbWasPressed && len(theComponent.Meshes) > 0 && !isMouseOverGUI() {
+		rayOrigin, rayDir := getMouseRay(w, perspective, view)
+		axis := gizmo.PickAxis(rayOrigin, rayDir)
+		if axis >= 0 {
+			gizmo.BeginDrag(axis, &theComponent)
+		}
+	} else if lmbPressed && gizmo.IsDragging() {
+		gizmo.OnDrag(float32(mx-lastCursorX), float32(my-lastCursorY), &theComponent)
+	} else if !lmbPressed {
+		gizmo.EndDrag()
+	}
+
+	lastCursorX = mx
+	lastCursorY = my
+	lmbWasPressed = lmbPressed
+}
+
 // onWindowResize is called when the window changes size
 func onWindowResize(w *glfw.Window, width int, height int) {
 	uiman.AdviseResolution(int32(width), int32(height))
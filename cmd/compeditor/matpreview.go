@@ -0,0 +1,207 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package main
+
+import (
+	"fmt"
+	"math"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	gui "github.com/tbogdala/eweygewey"
+
+	fizzle "github.com/tbogdala/fizzle"
+	component "github.com/tbogdala/fizzle/component"
+	graphics "github.com/tbogdala/fizzle/graphicsprovider"
+)
+
+const (
+	// materialPreviewSize is the width and height in pixels of the
+	// material preview image.
+	materialPreviewSize = 128
+)
+
+// MaterialPreview renders a unit sphere with a material into an offscreen
+// framebuffer so that the material can be shown in the user interface.
+// The sphere is only rendered again when the material changes.
+type MaterialPreview struct {
+	// Texture is the color attachment the sphere is rendered into.
+	Texture graphics.Texture
+
+	// fbo is the framebuffer the sphere is rendered with and depth is
+	// its depth attachment.
+	fbo   graphics.Buffer
+	depth graphics.Buffer
+
+	// sphere is the renderable the material gets applied to.
+	sphere *fizzle.Renderable
+
+	// camera looks at the sphere from the front.
+	camera *fizzle.OrbitCamera
+
+	// textureIndex is the index of Texture in the user interface texture stack.
+	textureIndex uint32
+
+	// material is a copy of the material last rendered and mesh is the
+	// mesh it belongs to. dirty is set when either one changes.
+	material component.Material
+	mesh     *component.Mesh
+	dirty    bool
+}
+
+// NewMaterialPreview creates the offscreen render target and the sphere for the
+// preview. An error is returned if the framebuffer is not complete.
+func NewMaterialPreview(gfx graphics.GraphicsProvider) (*MaterialPreview, error) {
+	mp := new(MaterialPreview)
+	mp.dirty = true
+
+	// setup the texture to render the sphere into
+	mp.Texture = gfx.GenTexture()
+	gfx.BindTexture(graphics.TEXTURE_2D, mp.Texture)
+	gfx.TexImage2D(graphics.TEXTURE_2D, 0, graphics.RGBA8, materialPreviewSize, materialPreviewSize, 0, graphics.RGBA, graphics.UNSIGNED_BYTE, nil, 0)
+	gfx.TexParameterf(graphics.TEXTURE_2D, graphics.TEXTURE_MAG_FILTER, graphics.LINEAR)
+	gfx.TexParameterf(graphics.TEXTURE_2D, graphics.TEXTURE_MIN_FILTER, graphics.LINEAR)
+	gfx.TexParameterf(graphics.TEXTURE_2D, graphics.TEXTURE_WRAP_S, graphics.CLAMP_TO_EDGE)
+	gfx.TexParameterf(graphics.TEXTURE_2D, graphics.TEXTURE_WRAP_T, graphics.CLAMP_TO_EDGE)
+	gfx.BindTexture(graphics.TEXTURE_2D, 0)
+
+	// setup the depth buffer
+	mp.depth = gfx.GenRenderbuffer()
+	gfx.BindRenderbuffer(graphics.RENDERBUFFER, mp.depth)
+	gfx.RenderbufferStorage(graphics.RENDERBUFFER, graphics.DEPTH_COMPONENT24, materialPreviewSize, materialPreviewSize)
+
+	// attach them to the framebuffer
+	mp.fbo = gfx.GenFramebuffer()
+	gfx.BindFramebuffer(graphics.FRAMEBUFFER, mp.fbo)
+	gfx.FramebufferRenderbuffer(graphics.FRAMEBUFFER, graphics.DEPTH_ATTACHMENT, graphics.RENDERBUFFER, mp.depth)
+	gfx.FramebufferTexture2D(graphics.FRAMEBUFFER, graphics.COLOR_ATTACHMENT0, graphics.TEXTURE_2D, mp.Texture, 0)
+	status := gfx.CheckFramebufferStatus(graphics.FRAMEBUFFER)
+	gfx.BindFramebuffer(graphics.FRAMEBUFFER, 0)
+	if status != graphics.FRAMEBUFFER_COMPLETE {
+		mp.Destroy(gfx)
+		return nil, fmt.Errorf("Failed to create the material preview framebuffer. Code 0x%x\n", status)
+	}
+
+	// create the unit sphere to preview the material with
+	sphereComp, err := component.CreatePrimitive(component.PrimSphere, component.DefaultPrimitiveParams())
+	if err != nil {
+		mp.Destroy(gfx)
+		return nil, fmt.Errorf("Failed to create the material preview sphere.\n%v\n", err)
+	}
+	mp.sphere = fizzle.CreateFromGombz(sphereComp.Meshes[0].SrcMesh)
+	mp.camera = fizzle.NewOrbitCamera(mgl.Vec3{0, 0, 0}, math.Pi/2.0, 1.5, math.Pi/2.0)
+
+	return mp, nil
+}
+
+// Destroy releases the graphics objects used by the preview.
+func (mp *MaterialPreview) Destroy(gfx graphics.GraphicsProvider) {
+	if mp.sphere != nil {
+		mp.sphere.Destroy()
+		mp.sphere = nil
+	}
+	gfx.DeleteFramebuffer(mp.fbo)
+	gfx.DeleteRenderbuffer(mp.depth)
+	gfx.DeleteTexture(mp.Texture)
+}
+
+// SetMaterial checks the material of the mesh against the one last rendered
+// and flags the preview to be rendered again if anything is different.
+func (mp *MaterialPreview) SetMaterial(compMesh *component.Mesh) {
+	if compMesh == nil {
+		return
+	}
+	if compMesh != mp.mesh || !materialsEqual(&mp.material, &compMesh.Material) {
+		mp.mesh = compMesh
+		mp.material = compMesh.Material
+		mp.material.Textures = append(compMesh.Material.Textures[:0:0], compMesh.Material.Textures...)
+		mp.dirty = true
+	}
+}
+
+// MarkDirty flags the preview to be rendered again, such as when a texture
+// the material uses has been reloaded.
+func (mp *MaterialPreview) MarkDirty() {
+	mp.dirty = true
+}
+
+// IsDirty returns true if the preview needs to be rendered again.
+func (mp *MaterialPreview) IsDirty() bool {
+	return mp.dirty
+}
+
+// Render draws the sphere with the material into the preview texture if the
+// preview is dirty. The viewport is left set to the preview size so callers
+// need to restore it.
+func (mp *MaterialPreview) Render(gfx graphics.GraphicsProvider) {
+	if !mp.dirty || mp.mesh == nil {
+		return
+	}
+
+	// start from a fresh material so textures from the last one don't linger
+	mp.sphere.Material = fizzle.NewMaterial()
+	mp.sphere.Material.Shader = shaders["Basic"]
+	applyMaterial(mp.sphere, &mp.material)
+
+	gfx.BindFramebuffer(graphics.FRAMEBUFFER, mp.fbo)
+	gfx.Viewport(0, 0, materialPreviewSize, materialPreviewSize)
+	gfx.ClearColor(clearColor[0], clearColor[1], clearColor[2], clearColor[3])
+	gfx.Clear(graphics.COLOR_BUFFER_BIT | graphics.DEPTH_BUFFER_BIT)
+
+	perspective := mgl.Perspective(mgl.DegToRad(60.0), 1.0, 0.1, 10.0)
+	renderer.DrawRenderable(mp.sphere, nil, perspective, mp.camera.GetViewMatrix(), mp.camera)
+
+	gfx.BindFramebuffer(graphics.FRAMEBUFFER, 0)
+	mp.dirty = false
+}
+
+// materialsEqual returns true if all of the fields of the materials match.
+func materialsEqual(a, b *component.Material) bool {
+	if a.ShaderName != b.ShaderName || a.Diffuse != b.Diffuse || a.Specular != b.Specular ||
+		a.Shininess != b.Shininess || a.GenerateMipmaps != b.GenerateMipmaps ||
+		a.DiffuseTexture != b.DiffuseTexture || a.NormalsTexture != b.NormalsTexture ||
+		a.SpecularTexture != b.SpecularTexture || len(a.Textures) != len(b.Textures) {
+		return false
+	}
+	for i := range a.Textures {
+		if a.Textures[i] != b.Textures[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// renderMaterialPreview renders the material preview for the active mesh if
+// its material has changed since the last time it was rendered.
+func renderMaterialPreview(gfx graphics.GraphicsProvider) {
+	if materialPreview == nil || activeMesh == nil {
+		return
+	}
+
+	materialPreview.SetMaterial(activeMesh)
+	if materialPreview.IsDirty() {
+		materialPreview.Render(gfx)
+		width, height := renderer.GetResolution()
+		gfx.Viewport(0, 0, int32(width), int32(height))
+	}
+}
+
+// guiAddMaterialPreview adds the material preview image to the mesh window
+// if the mesh is the active one.
+func guiAddMaterialPreview(wnd *gui.Window, compMesh *component.Mesh) {
+	if materialPreview == nil {
+		return
+	}
+
+	wnd.StartRow()
+	wnd.RequestItemWidthMin(textWidth)
+	wnd.Text("Preview")
+	if compMesh != activeMesh {
+		wnd.Text("Select the mesh to preview its material.")
+		return
+	}
+
+	previewWS, previewHS := uiman.DisplayToScreen(materialPreviewSize, materialPreviewSize)
+	wnd.Image(fmt.Sprintf("MaterialPreview%p", compMesh), previewWS, previewHS,
+		mgl.Vec4{1, 1, 1, 1}, materialPreview.textureIndex, mgl.Vec4{0, 0, 1, 1})
+}
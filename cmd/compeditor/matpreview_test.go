@@ -0,0 +1,255 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package main
+
+import (
+	"testing"
+	"unsafe"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+
+	fizzle "github.com/tbogdala/fizzle"
+	component "github.com/tbogdala/fizzle/component"
+	graphics "github.com/tbogdala/fizzle/graphicsprovider"
+)
+
+func TestMaterialsEqual(t *testing.T) {
+	base := component.Material{
+		ShaderName:      "Basic",
+		Diffuse:         mgl.Vec4{1, 1, 1, 1},
+		Specular:        mgl.Vec4{0.5, 0.5, 0.5, 1},
+		Shininess:       0.25,
+		GenerateMipmaps: true,
+		DiffuseTexture:  "diffuse.png",
+		Textures:        []string{"a.png", "b.png"},
+	}
+
+	tests := []struct {
+		change   func(m *component.Material)
+		expected bool
+	}{
+		{func(m *component.Material) {}, true},
+		{func(m *component.Material) { m.ShaderName = "Color" }, false},
+		{func(m *component.Material) { m.Diffuse[2] = 0.5 }, false},
+		{func(m *component.Material) { m.Specular[3] = 0.0 }, false},
+		{func(m *component.Material) { m.Shininess = 0.5 }, false},
+		{func(m *component.Material) { m.GenerateMipmaps = false }, false},
+		{func(m *component.Material) { m.DiffuseTexture = "" }, false},
+		{func(m *component.Material) { m.NormalsTexture = "normals.png" }, false},
+		{func(m *component.Material) { m.SpecularTexture = "specular.png" }, false},
+		{func(m *component.Material) { m.Textures[1] = "c.png" }, false},
+		{func(m *component.Material) { m.Textures = m.Textures[:1] }, false},
+	}
+
+	for i, test := range tests {
+		changed := base
+		changed.Textures = append([]string{}, base.Textures...)
+		test.change(&changed)
+		if equal := materialsEqual(&base, &changed); equal != test.expected {
+			t.Errorf("materialsEqual for change %d returned %v; expected %v", i, equal, test.expected)
+		}
+	}
+}
+
+func TestMaterialPreviewSetMaterialDirty(t *testing.T) {
+	mp := new(MaterialPreview)
+	compMesh := component.NewMesh()
+	compMesh.Material.Textures = []string{"a.png"}
+
+	// nil meshes are ignored
+	mp.SetMaterial(nil)
+	if mp.IsDirty() {
+		t.Errorf("SetMaterial with a nil mesh marked the preview dirty.")
+	}
+
+	mp.SetMaterial(compMesh)
+	if !mp.IsDirty() {
+		t.Errorf("SetMaterial with a new mesh didn't mark the preview dirty.")
+	}
+
+	mp.dirty = false
+	mp.SetMaterial(compMesh)
+	if mp.IsDirty() {
+		t.Errorf("SetMaterial with an unchanged material marked the preview dirty.")
+	}
+
+	compMesh.Material.Shininess = 0.75
+	mp.SetMaterial(compMesh)
+	if !mp.IsDirty() {
+		t.Errorf("SetMaterial with a changed shininess didn't mark the preview dirty.")
+	}
+
+	// the textures are copied so changing them in place is still noticed
+	mp.dirty = false
+	compMesh.Material.Textures[0] = "b.png"
+	mp.SetMaterial(compMesh)
+	if !mp.IsDirty() {
+		t.Errorf("SetMaterial with a texture changed in place didn't mark the preview dirty.")
+	}
+
+	// switching to another mesh with the same material is still a change
+	mp.dirty = false
+	otherMesh := component.NewMesh()
+	otherMesh.Material = compMesh.Material
+	otherMesh.Material.Textures = []string{"b.png"}
+	mp.SetMaterial(otherMesh)
+	if !mp.IsDirty() {
+		t.Errorf("SetMaterial with a different mesh didn't mark the preview dirty.")
+	}
+
+	mp.dirty = false
+	mp.MarkDirty()
+	if !mp.IsDirty() {
+		t.Errorf("MarkDirty didn't mark the preview dirty.")
+	}
+}
+
+// mockPreviewGraphics records the render target objects created for the
+// material preview and the ones deleted. Calling any GraphicsProvider method
+// it doesn't implement panics.
+type mockPreviewGraphics struct {
+	graphics.GraphicsProvider
+
+	next        uint32
+	status      graphics.Enum
+	texture     graphics.Texture
+	renderbuf   graphics.Buffer
+	framebuf    graphics.Buffer
+	textureSize [2]int32
+	depthSize   [2]int32
+	attachments map[graphics.Buffer]map[graphics.Enum]uint32
+
+	deletedTextures      []graphics.Texture
+	deletedRenderbuffers []graphics.Buffer
+	deletedFramebuffers  []graphics.Buffer
+}
+
+func newMockPreviewGraphics(status graphics.Enum) *mockPreviewGraphics {
+	g := new(mockPreviewGraphics)
+	g.status = status
+	g.attachments = make(map[graphics.Buffer]map[graphics.Enum]uint32)
+	return g
+}
+
+func (g *mockPreviewGraphics) gen() uint32 {
+	g.next++
+	return g.next
+}
+
+func (g *mockPreviewGraphics) GenTexture() graphics.Texture        { return graphics.Texture(g.gen()) }
+func (g *mockPreviewGraphics) GenRenderbuffer() graphics.Buffer    { return graphics.Buffer(g.gen()) }
+func (g *mockPreviewGraphics) GenFramebuffer() graphics.Buffer     { return graphics.Buffer(g.gen()) }
+func (g *mockPreviewGraphics) GenBuffer() graphics.Buffer          { return graphics.Buffer(g.gen()) }
+func (g *mockPreviewGraphics) GenVertexArray() uint32              { return g.gen() }
+func (g *mockPreviewGraphics) Ptr(data interface{}) unsafe.Pointer { return nil }
+
+func (g *mockPreviewGraphics) BindTexture(target graphics.Enum, t graphics.Texture) { g.texture = t }
+func (g *mockPreviewGraphics) BindRenderbuffer(target graphics.Enum, rb graphics.Buffer) {
+	g.renderbuf = rb
+}
+func (g *mockPreviewGraphics) BindFramebuffer(target graphics.Enum, fb graphics.Buffer) {
+	g.framebuf = fb
+	if fb != 0 && g.attachments[fb] == nil {
+		g.attachments[fb] = make(map[graphics.Enum]uint32)
+	}
+}
+func (g *mockPreviewGraphics) BindBuffer(target graphics.Enum, b graphics.Buffer) {}
+func (g *mockPreviewGraphics) BufferData(target graphics.Enum, size int, data unsafe.Pointer, usage graphics.Enum) {
+}
+func (g *mockPreviewGraphics) TexParameterf(target, pname graphics.Enum, param float32) {}
+
+func (g *mockPreviewGraphics) TexImage2D(target graphics.Enum, level, intfmt, width, height, border int32, format graphics.Enum, ty graphics.Enum, ptr unsafe.Pointer, dataLength int) {
+	g.textureSize = [2]int32{width, height}
+}
+
+func (g *mockPreviewGraphics) RenderbufferStorage(target graphics.Enum, internalformat graphics.Enum, width int32, height int32) {
+	g.depthSize = [2]int32{width, height}
+}
+
+func (g *mockPreviewGraphics) FramebufferRenderbuffer(target, attachment, renderbuffertarget graphics.Enum, renderbuffer graphics.Buffer) {
+	g.attachments[g.framebuf][attachment] = uint32(renderbuffer)
+}
+
+func (g *mockPreviewGraphics) FramebufferTexture2D(target, attachment, textarget graphics.Enum, texture graphics.Texture, level int32) {
+	g.attachments[g.framebuf][attachment] = uint32(texture)
+}
+
+func (g *mockPreviewGraphics) CheckFramebufferStatus(target graphics.Enum) graphics.Enum {
+	return g.status
+}
+
+func (g *mockPreviewGraphics) DeleteTexture(t graphics.Texture) {
+	g.deletedTextures = append(g.deletedTextures, t)
+}
+func (g *mockPreviewGraphics) DeleteRenderbuffer(rb graphics.Buffer) {
+	g.deletedRenderbuffers = append(g.deletedRenderbuffers, rb)
+}
+func (g *mockPreviewGraphics) DeleteFramebuffer(fb graphics.Buffer) {
+	g.deletedFramebuffers = append(g.deletedFramebuffers, fb)
+}
+func (g *mockPreviewGraphics) DeleteBuffer(b graphics.Buffer) {}
+func (g *mockPreviewGraphics) DeleteVertexArray(a uint32)     {}
+
+// checkPreviewDeleted reports an error if the render target objects of the
+// preview were not each deleted once.
+func checkPreviewDeleted(t *testing.T, g *mockPreviewGraphics, texture graphics.Texture, depth, fbo graphics.Buffer) {
+	if len(g.deletedTextures) != 1 || g.deletedTextures[0] != texture {
+		t.Errorf("The deleted textures are %v; expected [%d]", g.deletedTextures, texture)
+	}
+	if len(g.deletedRenderbuffers) != 1 || g.deletedRenderbuffers[0] != depth {
+		t.Errorf("The deleted renderbuffers are %v; expected [%d]", g.deletedRenderbuffers, depth)
+	}
+	if len(g.deletedFramebuffers) != 1 || g.deletedFramebuffers[0] != fbo {
+		t.Errorf("The deleted framebuffers are %v; expected [%d]", g.deletedFramebuffers, fbo)
+	}
+}
+
+func TestNewMaterialPreview(t *testing.T) {
+	g := newMockPreviewGraphics(graphics.FRAMEBUFFER_COMPLETE)
+	defer fizzle.SetGraphics(fizzle.GetGraphics())
+	fizzle.SetGraphics(g)
+
+	mp, err := NewMaterialPreview(g)
+	if err != nil {
+		t.Fatalf("NewMaterialPreview returned an error: %v", err)
+	}
+
+	expectedSize := [2]int32{materialPreviewSize, materialPreviewSize}
+	if g.textureSize != expectedSize {
+		t.Errorf("The preview texture is %v; expected %v", g.textureSize, expectedSize)
+	}
+	if g.depthSize != expectedSize {
+		t.Errorf("The preview depth buffer is %v; expected %v", g.depthSize, expectedSize)
+	}
+	attached := g.attachments[mp.fbo]
+	if mp.fbo == 0 || attached[graphics.COLOR_ATTACHMENT0] != uint32(mp.Texture) || attached[graphics.DEPTH_ATTACHMENT] != uint32(mp.depth) {
+		t.Errorf("Framebuffer %d has attachments %v; expected texture %d and depth buffer %d", mp.fbo, attached, mp.Texture, mp.depth)
+	}
+	if g.framebuf != 0 || g.texture != 0 {
+		t.Errorf("NewMaterialPreview left framebuffer %d and texture %d bound", g.framebuf, g.texture)
+	}
+	if mp.sphere == nil || !mp.IsDirty() {
+		t.Errorf("NewMaterialPreview didn't create the sphere or mark the preview dirty.")
+	}
+
+	mp.Destroy(g)
+	checkPreviewDeleted(t, g, mp.Texture, mp.depth, mp.fbo)
+	if mp.sphere != nil {
+		t.Errorf("Destroy didn't release the preview sphere.")
+	}
+}
+
+func TestNewMaterialPreviewIncomplete(t *testing.T) {
+	g := newMockPreviewGraphics(graphics.FRAMEBUFFER_UNSUPPORTED)
+	defer fizzle.SetGraphics(fizzle.GetGraphics())
+	fizzle.SetGraphics(g)
+
+	mp, err := NewMaterialPreview(g)
+	if err == nil || mp != nil {
+		t.Fatalf("NewMaterialPreview with an incomplete framebuffer should fail.")
+	}
+
+	// the objects are numbered in the order they are created
+	checkPreviewDeleted(t, g, 1, 2, 3)
+}
@@ -0,0 +1,152 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	gui "github.com/tbogdala/eweygewey"
+
+	forward "github.com/tbogdala/fizzle/renderer/forward"
+)
+
+const (
+	// editorPrefsFilename is the file the editor preferences are stored in.
+	editorPrefsFilename = "compeditor_prefs.json"
+
+	minVerticalFOV = 10.0
+	maxVerticalFOV = 170.0
+	minNearDist    = 0.001
+	maxNearDist    = 10.0
+	minFarDist     = 1.0
+	maxFarDist     = 10000.0
+)
+
+// EditorPrefs are the editor settings that persist between runs.
+type EditorPrefs struct {
+	// VerticalFOV is the vertical field of view of the camera in degrees.
+	VerticalFOV float32 `json:"vfov"`
+
+	// NearDist is the distance to the near clipping plane.
+	NearDist float32 `json:"near"`
+
+	// FarDist is the distance to the far clipping plane.
+	FarDist float32 `json:"far"`
+}
+
+// defaultEditorPrefs returns the preferences used when none have been saved.
+func defaultEditorPrefs() EditorPrefs {
+	return EditorPrefs{
+		VerticalFOV: 60.0,
+		NearDist:    0.1,
+		FarDist:     100.0,
+	}
+}
+
+// validateRenderParams returns an error if the field of view in degrees and
+// the clipping plane distances can't be used to build a perspective matrix.
+func validateRenderParams(vfov, near, far float32) error {
+	if vfov < minVerticalFOV || vfov > maxVerticalFOV {
+		return fmt.Errorf("The field of view must be between %.0f and %.0f degrees.", minVerticalFOV, maxVerticalFOV)
+	}
+	if near <= 0.0 {
+		return fmt.Errorf("The near distance must be greater than 0.")
+	}
+	if near >= far {
+		return fmt.Errorf("The near distance must be less than the far distance.")
+	}
+	return nil
+}
+
+// loadEditorPrefs loads the preferences from the file specified. If the file
+// doesn't exist the default preferences are returned without an error.
+func loadEditorPrefs(filepath string) (EditorPrefs, error) {
+	prefs := defaultEditorPrefs()
+	prefsJSON, err := ioutil.ReadFile(filepath)
+	if os.IsNotExist(err) {
+		return prefs, nil
+	} else if err != nil {
+		return prefs, fmt.Errorf("Failed to read the editor preferences file %s.\n%v\n", filepath, err)
+	}
+
+	err = json.Unmarshal(prefsJSON, &prefs)
+	if err != nil {
+		return defaultEditorPrefs(), fmt.Errorf("Failed to decode the editor preferences file %s.\n%v\n", filepath, err)
+	}
+
+	err = validateRenderParams(prefs.VerticalFOV, prefs.NearDist, prefs.FarDist)
+	if err != nil {
+		return defaultEditorPrefs(), fmt.Errorf("Invalid renderer settings in the editor preferences file %s.\n%v\n", filepath, err)
+	}
+
+	return prefs, nil
+}
+
+// saveEditorPrefs writes the preferences to the file specified.
+func saveEditorPrefs(filepath string, prefs EditorPrefs) error {
+	prefsJSON, err := json.MarshalIndent(prefs, "", "    ")
+	if err != nil {
+		return fmt.Errorf("Failed to serialize the editor preferences to JSON: %v\n", err)
+	}
+
+	err = ioutil.WriteFile(filepath, prefsJSON, 0644)
+	if err != nil {
+		return fmt.Errorf("Failed to write the editor preferences file %s.\n%v\n", filepath, err)
+	}
+
+	return nil
+}
+
+// createRendererSettingsWindow creates the window for the camera and renderer settings.
+func createRendererSettingsWindow(sX, sY, sW, sH float32) *gui.Window {
+	settingsWindow := uiman.NewWindow("RendererSettings", sX, sY, sW, sH, func(wnd *gui.Window) {
+		renderRendererSettings(wnd)
+	})
+	settingsWindow.Title = "Renderer Settings"
+	settingsWindow.ShowTitleBar = true
+	settingsWindow.IsMoveable = true
+	return settingsWindow
+}
+
+// renderRendererSettings does the user interface for the perspective settings.
+// Changes are only applied to editorPrefs if they pass validateRenderParams.
+func renderRendererSettings(wnd *gui.Window) {
+	vfov := editorPrefs.VerticalFOV
+	near := editorPrefs.NearDist
+	far := editorPrefs.FarDist
+
+	wnd.StartRow()
+	wnd.RequestItemWidthMin(textWidth)
+	wnd.Text("FOV")
+	wnd.SliderFloat("rendererFOV", &vfov, minVerticalFOV, maxVerticalFOV)
+
+	wnd.StartRow()
+	wnd.RequestItemWidthMin(textWidth)
+	wnd.Text("Near")
+	wnd.SliderFloat("rendererNear", &near, minNearDist, maxNearDist)
+
+	wnd.StartRow()
+	wnd.RequestItemWidthMin(textWidth)
+	wnd.Text("Far")
+	wnd.SliderFloat("rendererFar", &far, minFarDist, maxFarDist)
+
+	wnd.StartRow()
+	wnd.RequestItemWidthMin(textWidth)
+	wnd.Text("Outline")
+	wnd.SliderFloat("rendererOutline", &outlineThickness, forward.MinOutlineThickness, forward.MaxOutlineThickness)
+
+	err := validateRenderParams(vfov, near, far)
+	if err != nil {
+		wnd.StartRow()
+		wnd.Text(err.Error())
+		return
+	}
+
+	editorPrefs.VerticalFOV = vfov
+	editorPrefs.NearDist = near
+	editorPrefs.FarDist = far
+}
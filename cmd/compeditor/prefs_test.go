@@ -0,0 +1,70 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateRenderParams(t *testing.T) {
+	tests := []struct {
+		vfov, near, far float32
+		valid           bool
+	}{
+		{60.0, 0.1, 100.0, true},
+		{minVerticalFOV, 0.1, 100.0, true},
+		{maxVerticalFOV, 0.1, 100.0, true},
+		{minVerticalFOV - 1.0, 0.1, 100.0, false},
+		{maxVerticalFOV + 1.0, 0.1, 100.0, false},
+		{0.0, 0.1, 100.0, false},
+		{180.0, 0.1, 100.0, false},
+		{60.0, 0.0, 100.0, false},
+		{60.0, -1.0, 100.0, false},
+		{60.0, 100.0, 100.0, false},
+		{60.0, 10.0, 1.0, false},
+	}
+
+	for _, test := range tests {
+		err := validateRenderParams(test.vfov, test.near, test.far)
+		if (err == nil) != test.valid {
+			t.Errorf("validateRenderParams(%f, %f, %f) returned %v; expected valid to be %v",
+				test.vfov, test.near, test.far, err, test.valid)
+		}
+	}
+}
+
+func TestSaveAndLoadEditorPrefs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "compeditor_prefs")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	prefsPath := filepath.Join(dir, editorPrefsFilename)
+
+	prefs, err := loadEditorPrefs(prefsPath)
+	if err != nil || prefs != defaultEditorPrefs() {
+		t.Errorf("Loading a missing prefs file should return the defaults; got %v and %v", prefs, err)
+	}
+
+	saved := EditorPrefs{VerticalFOV: 45.0, NearDist: 0.5, FarDist: 250.0}
+	err = saveEditorPrefs(prefsPath, saved)
+	if err != nil {
+		t.Fatalf("Failed to save the prefs: %v", err)
+	}
+	info, err := os.Stat(prefsPath)
+	if err != nil {
+		t.Fatalf("Failed to stat the prefs file: %v", err)
+	}
+	if info.Mode().Perm()&0111 != 0 {
+		t.Errorf("The prefs file was written as executable: %v", info.Mode())
+	}
+
+	prefs, err = loadEditorPrefs(prefsPath)
+	if err != nil || prefs != saved {
+		t.Errorf("Loaded prefs %v (%v); expected %v", prefs, err, saved)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package main
+
+import (
+	mgl "github.com/go-gl/mathgl/mgl32"
+
+	fizzle "github.com/tbogdala/fizzle"
+	graphics "github.com/tbogdala/fizzle/graphicsprovider"
+	forward "github.com/tbogdala/fizzle/renderer/forward"
+)
+
+const (
+	// sparklineSampleCount is the number of samples a Sparkline keeps.
+	sparklineSampleCount = 128
+)
+
+// Sparkline keeps the most recent samples of a statistic in a ring buffer
+// and draws them as a small line graph so that spikes are easy to spot.
+type Sparkline struct {
+	// Color is the color the line is drawn with.
+	Color mgl.Vec4
+
+	// samples is the ring buffer of values and head is the index the
+	// next value will be written to.
+	samples [sparklineSampleCount]float32
+	head    int
+
+	// count is the number of samples pushed, up to sparklineSampleCount.
+	count int
+
+	// line is the renderable whose vertices are updated every Draw.
+	line *fizzle.Renderable
+}
+
+// NewSparkline creates a new Sparkline that draws with the color specified.
+func NewSparkline(color mgl.Vec4) *Sparkline {
+	sl := new(Sparkline)
+	sl.Color = color
+	return sl
+}
+
+// Push adds a sample, overwriting the oldest one once the buffer is full.
+func (sl *Sparkline) Push(v float32) {
+	sl.samples[sl.head] = v
+	sl.head = (sl.head + 1) % sparklineSampleCount
+	if sl.count < sparklineSampleCount {
+		sl.count++
+	}
+}
+
+// Len returns the number of samples in the Sparkline.
+func (sl *Sparkline) Len() int {
+	return sl.count
+}
+
+// At returns the sample at the index where 0 is the oldest sample.
+func (sl *Sparkline) At(i int) float32 {
+	start := (sl.head - sl.count + sparklineSampleCount) % sparklineSampleCount
+	return sl.samples[(start+i)%sparklineSampleCount]
+}
+
+// Last returns the most recent sample or 0 if there are none.
+func (sl *Sparkline) Last() float32 {
+	if sl.count == 0 {
+		return 0.0
+	}
+	return sl.At(sl.count - 1)
+}
+
+// MinMax returns the smallest and largest samples in the Sparkline.
+func (sl *Sparkline) MinMax() (float32, float32) {
+	if sl.count == 0 {
+		return 0.0, 0.0
+	}
+
+	min, max := sl.At(0), sl.At(0)
+	for i := 1; i < sl.count; i++ {
+		v := sl.At(i)
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return min, max
+}
+
+// scaleSample maps the value into the range [0..1] where min is 0 and max is 1.
+// Values outside of the range are clamped to it.
+func scaleSample(v, min, max float32) float32 {
+	if max <= min {
+		return 0.0
+	}
+
+	scaled := (v - min) / (max - min)
+	if scaled < 0.0 {
+		return 0.0
+	} else if scaled > 1.0 {
+		return 1.0
+	}
+	return scaled
+}
+
+// Draw renders the samples as a line graph in the screen rectangle specified
+// in pixels from the bottom left corner of the window. Samples equal to min
+// are drawn at the bottom and samples equal to max are drawn at the top.
+// The shader should be a flat color shader.
+func (sl *Sparkline) Draw(renderer *forward.ForwardRenderer, shader *fizzle.RenderShader, camera fizzle.Camera,
+	x, y, w, h float32, min, max float32) {
+	if sl.count < 2 {
+		return
+	}
+
+	const floatSize = 4
+	gfx := renderer.GetGraphics()
+	if sl.line == nil {
+		sl.line = createSparklineRenderable(gfx)
+	}
+
+	verts := make([]float32, 0, sparklineSampleCount*3)
+	step := w / float32(sparklineSampleCount-1)
+	for i := 0; i < sl.count; i++ {
+		vx := x + float32(i)*step
+		vy := y + scaleSample(sl.At(i), min, max)*h
+		verts = append(verts, vx, vy, 0.0)
+	}
+	gfx.BindBuffer(graphics.ARRAY_BUFFER, sl.line.Core.VertVBO)
+	gfx.BufferData(graphics.ARRAY_BUFFER, floatSize*len(verts), gfx.Ptr(&verts[0]), graphics.DYNAMIC_DRAW)
+
+	sl.line.FaceCount = uint32(sl.count - 1)
+	sl.line.Material.Shader = shader
+	sl.line.Material.DiffuseColor = sl.Color
+
+	width, height := renderer.GetResolution()
+	ortho := mgl.Ortho(0, float32(width), 0, float32(height), -1, 1)
+	renderer.DrawLines(sl.line, shader, nil, ortho, mgl.Ident4(), camera)
+}
+
+// Destroy releases the renderable for the Sparkline.
+func (sl *Sparkline) Destroy() {
+	if sl.line != nil {
+		sl.line.Destroy()
+		sl.line = nil
+	}
+}
+
+// createSparklineRenderable makes a renderable with enough vertices for every
+// sample connected in order by lines.
+func createSparklineRenderable(gfx graphics.GraphicsProvider) *fizzle.Renderable {
+	const floatSize = 4
+	const uintSize = 4
+
+	r := fizzle.NewRenderable()
+	r.Core = fizzle.NewRenderableCore()
+	r.Material = fizzle.NewMaterial()
+
+	verts := make([]float32, sparklineSampleCount*3)
+	indexes := make([]uint32, 0, (sparklineSampleCount-1)*2)
+	for i := uint32(0); i < sparklineSampleCount-1; i++ {
+		indexes = append(indexes, i, i+1)
+	}
+
+	r.Core.VertVBO = gfx.GenBuffer()
+	gfx.BindBuffer(graphics.ARRAY_BUFFER, r.Core.VertVBO)
+	gfx.BufferData(graphics.ARRAY_BUFFER, floatSize*len(verts), gfx.Ptr(&verts[0]), graphics.DYNAMIC_DRAW)
+
+	r.Core.ElementsVBO = gfx.GenBuffer()
+	gfx.BindBuffer(graphics.ELEMENT_ARRAY_BUFFER, r.Core.ElementsVBO)
+	gfx.BufferData(graphics.ELEMENT_ARRAY_BUFFER, uintSize*len(indexes), gfx.Ptr(&indexes[0]), graphics.STATIC_DRAW)
+
+	return r
+}
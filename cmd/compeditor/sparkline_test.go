@@ -0,0 +1,79 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package main
+
+import (
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+func TestSparklineWraparound(t *testing.T) {
+	sl := NewSparkline(mgl.Vec4{1, 1, 1, 1})
+	if sl.Len() != 0 || sl.Last() != 0.0 {
+		t.Errorf("A new sparkline should have no samples.")
+	}
+	if min, max := sl.MinMax(); min != 0.0 || max != 0.0 {
+		t.Errorf("An empty sparkline returned a range of %f..%f", min, max)
+	}
+
+	// push more samples than fit so that the oldest ones get overwritten
+	const pushed = sparklineSampleCount + 10
+	for i := 0; i < pushed; i++ {
+		sl.Push(float32(i))
+	}
+
+	if sl.Len() != sparklineSampleCount {
+		t.Fatalf("The sparkline has %d samples; expected %d", sl.Len(), sparklineSampleCount)
+	}
+	for i := 0; i < sl.Len(); i++ {
+		expected := float32(pushed - sparklineSampleCount + i)
+		if sl.At(i) != expected {
+			t.Fatalf("Sample %d is %f; expected %f", i, sl.At(i), expected)
+		}
+	}
+	if sl.Last() != float32(pushed-1) {
+		t.Errorf("The last sample is %f; expected %f", sl.Last(), float32(pushed-1))
+	}
+
+	min, max := sl.MinMax()
+	if min != float32(pushed-sparklineSampleCount) || max != float32(pushed-1) {
+		t.Errorf("The sparkline range is %f..%f; expected %f..%f", min, max,
+			float32(pushed-sparklineSampleCount), float32(pushed-1))
+	}
+}
+
+func TestSparklinePartial(t *testing.T) {
+	sl := NewSparkline(mgl.Vec4{1, 1, 1, 1})
+	sl.Push(3.0)
+	sl.Push(-1.0)
+	sl.Push(2.0)
+
+	if sl.Len() != 3 || sl.At(0) != 3.0 || sl.At(1) != -1.0 || sl.Last() != 2.0 {
+		t.Errorf("The samples were not kept in the order pushed.")
+	}
+	if min, max := sl.MinMax(); min != -1.0 || max != 3.0 {
+		t.Errorf("The sparkline range is %f..%f; expected -1..3", min, max)
+	}
+}
+
+func TestScaleSample(t *testing.T) {
+	tests := []struct {
+		v, min, max, expected float32
+	}{
+		{5.0, 0.0, 10.0, 0.5},
+		{0.0, 0.0, 10.0, 0.0},
+		{10.0, 0.0, 10.0, 1.0},
+		{-5.0, 0.0, 10.0, 0.0},
+		{15.0, 0.0, 10.0, 1.0},
+		{5.0, 5.0, 5.0, 0.0},
+	}
+
+	for _, test := range tests {
+		scaled := scaleSample(test.v, test.min, test.max)
+		if scaled != test.expected {
+			t.Errorf("scaleSample(%f, %f, %f) returned %f; expected %f", test.v, test.min, test.max, scaled, test.expected)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package main
+
+import (
+	"time"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	gombz "github.com/tbogdala/gombz"
+
+	fizzle "github.com/tbogdala/fizzle"
+	component "github.com/tbogdala/fizzle/component"
+)
+
+const (
+	// bindingCoalesceThreshold is the amount of time between two changes
+	// of a PropertyBinding for them to be merged into one undo entry.
+	bindingCoalesceThreshold = 500 * time.Millisecond
+
+	// cameraMoveThrottle is the length of a continuous camera drag that is
+	// merged into one CameraMoveCommand.
+	cameraMoveThrottle = 500 * time.Millisecond
+)
+
+// Command is an edit operation that can be undone and redone.
+type Command interface {
+	// Do applies the edit.
+	Do()
+
+	// Undo reverts the edit.
+	Undo()
+}
+
+// undoHistory keeps track of the commands that have been applied to the
+// component being edited so that they can be undone and redone.
+type undoHistory struct {
+	// done is the stack of commands that can be undone.
+	done []Command
+
+	// undone is the stack of commands that have been undone and can be redone.
+	undone []Command
+}
+
+// newUndoHistory creates a new undoHistory object with empty stacks.
+func newUndoHistory() *undoHistory {
+	h := new(undoHistory)
+	h.Clear()
+	return h
+}
+
+// Clear removes all of the commands from the history.
+func (h *undoHistory) Clear() {
+	h.done = []Command{}
+	h.undone = []Command{}
+}
+
+// Push adds a command that has already been applied to the history. This
+// clears out any commands that could have been redone.
+func (h *undoHistory) Push(cmd Command) {
+	h.done = append(h.done, cmd)
+	h.undone = h.undone[:0]
+}
+
+// Execute applies the command and then pushes it onto the history.
+func (h *undoHistory) Execute(cmd Command) {
+	cmd.Do()
+	h.Push(cmd)
+}
+
+// Top returns the last command applied or nil if there is none.
+func (h *undoHistory) Top() Command {
+	if len(h.done) == 0 {
+		return nil
+	}
+	return h.done[len(h.done)-1]
+}
+
+// Undo reverts the last command applied. Returns false if there was
+// nothing to undo.
+func (h *undoHistory) Undo() bool {
+	if len(h.done) == 0 {
+		return false
+	}
+
+	cmd := h.done[len(h.done)-1]
+	h.done = h.done[:len(h.done)-1]
+	cmd.Undo()
+	h.undone = append(h.undone, cmd)
+	return true
+}
+
+// Redo reapplies the last command undone. Returns false if there was
+// nothing to redo.
+func (h *undoHistory) Redo() bool {
+	if len(h.undone) == 0 {
+		return false
+	}
+
+	cmd := h.undone[len(h.undone)-1]
+	h.undone = h.undone[:len(h.undone)-1]
+	cmd.Do()
+	h.done = append(h.done, cmd)
+	return true
+}
+
+// propertyCommand is the Command pushed by a PropertyBinding when the
+// property value changes.
+type propertyCommand[T comparable] struct {
+	set      func(T)
+	oldValue T
+	newValue T
+}
+
+// Do sets the property to the new value.
+func (cmd *propertyCommand[T]) Do() {
+	cmd.set(cmd.newValue)
+}
+
+// Undo sets the property back to the old value.
+func (cmd *propertyCommand[T]) Undo() {
+	cmd.set(cmd.oldValue)
+}
+
+// meshDataCommand replaces the source mesh data of a component mesh and
+// recreates the renderable for it.
+type meshDataCommand struct {
+	compMesh *component.Mesh
+	oldMesh  *gombz.Mesh
+	newMesh  *gombz.Mesh
+}
+
+// Do sets the new mesh data.
+func (cmd *meshDataCommand) Do() {
+	cmd.compMesh.SrcMesh = cmd.newMesh
+	createMeshRenderable(cmd.compMesh)
+}
+
+// Undo sets the old mesh data back.
+func (cmd *meshDataCommand) Undo() {
+	cmd.compMesh.SrcMesh = cmd.oldMesh
+	createMeshRenderable(cmd.compMesh)
+}
+
+// meshTransform is a copy of the transform properties of a component mesh.
+type meshTransform struct {
+	Offset          mgl.Vec3
+	Scale           mgl.Vec3
+	RotationAxis    mgl.Vec3
+	RotationDegrees float32
+}
+
+// getMeshTransforms copies the transform properties of all of the meshes.
+func getMeshTransforms(meshes []*component.Mesh) []meshTransform {
+	transforms := make([]meshTransform, len(meshes))
+	for i, compMesh := range meshes {
+		transforms[i] = meshTransform{compMesh.Offset, compMesh.Scale, compMesh.RotationAxis, compMesh.RotationDegrees}
+	}
+	return transforms
+}
+
+// meshTransformsEqual returns true if the two sets of transforms match.
+func meshTransformsEqual(a, b []meshTransform) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// setMeshTransforms sets the transform properties for all of the meshes.
+func setMeshTransforms(meshes []*component.Mesh, transforms []meshTransform) {
+	for i, compMesh := range meshes {
+		compMesh.Offset = transforms[i].Offset
+		compMesh.Scale = transforms[i].Scale
+		compMesh.RotationAxis = transforms[i].RotationAxis
+		compMesh.RotationDegrees = transforms[i].RotationDegrees
+	}
+}
+
+// meshTransformCommand changes the transform properties of a set of meshes,
+// such as when the gizmo is dragged.
+type meshTransformCommand struct {
+	meshes        []*component.Mesh
+	oldTransforms []meshTransform
+	newTransforms []meshTransform
+}
+
+// Do sets the new transforms.
+func (cmd *meshTransformCommand) Do() {
+	setMeshTransforms(cmd.meshes, cmd.newTransforms)
+}
+
+// Undo sets the old transforms back.
+func (cmd *meshTransformCommand) Undo() {
+	setMeshTransforms(cmd.meshes, cmd.oldTransforms)
+}
+
+// CameraMoveCommand moves the camera between two states so that camera
+// movement can be undone.
+type CameraMoveCommand struct {
+	camera   *fizzle.OrbitCamera
+	oldState fizzle.CameraSnapshot
+	newState fizzle.CameraSnapshot
+}
+
+// Do moves the camera to the new state.
+func (cmd *CameraMoveCommand) Do() {
+	cmd.camera.RestoreCameraState(cmd.newState)
+}
+
+// Undo moves the camera back to the old state.
+func (cmd *CameraMoveCommand) Undo() {
+	cmd.camera.RestoreCameraState(cmd.oldState)
+}
+
+// cameraMoveTracker pushes CameraMoveCommands to the history while the camera
+// is being dragged, throttled so that at most one command is pushed for every
+// cameraMoveThrottle of continuous dragging.
+type cameraMoveTracker struct {
+	history *undoHistory
+
+	// lastCmd is the command for the current drag and lastCmdStart is when
+	// it was pushed. lastCmd is nil if no drag is in progress.
+	lastCmd      *CameraMoveCommand
+	lastCmdStart time.Time
+}
+
+// newCameraMoveTracker creates a tracker that pushes to the history specified.
+func newCameraMoveTracker(history *undoHistory) *cameraMoveTracker {
+	t := new(cameraMoveTracker)
+	t.history = history
+	return t
+}
+
+// Moved should be called after the camera was moved while dragging, with the
+// state the camera was in before the move and the current time.
+func (t *cameraMoveTracker) Moved(camera *fizzle.OrbitCamera, before fizzle.CameraSnapshot, now time.Time) {
+	after := camera.SaveCameraState()
+	if after == before {
+		return
+	}
+
+	if t.lastCmd != nil && t.history.Top() == Command(t.lastCmd) && now.Sub(t.lastCmdStart) < cameraMoveThrottle {
+		t.lastCmd.newState = after
+		return
+	}
+
+	t.lastCmd = &CameraMoveCommand{camera: camera, oldState: before, newState: after}
+	t.lastCmdStart = now
+	t.history.Push(t.lastCmd)
+}
+
+// EndDrag should be called when the camera drag stops so that the next
+// movement starts a new command.
+func (t *cameraMoveTracker) EndDrag() {
+	t.lastCmd = nil
+}
+
+// PropertyBinding binds a property of the component being edited to the
+// undo history so that every change made through Set can be undone.
+type PropertyBinding[T comparable] struct {
+	get     func() T
+	set     func(T)
+	history *undoHistory
+
+	// lastCmd is the last command pushed by the binding and lastSet is the
+	// time it was last updated; these are used to coalesce rapid changes.
+	lastCmd *propertyCommand[T]
+	lastSet time.Time
+}
+
+// NewPropertyBinding creates a new binding for a property using the get and set
+// functions to access the value and pushes changes to the history specified.
+func NewPropertyBinding[T comparable](history *undoHistory, get func() T, set func(T)) *PropertyBinding[T] {
+	b := new(PropertyBinding[T])
+	b.get = get
+	b.set = set
+	b.history = history
+	return b
+}
+
+// Get returns the current value of the property.
+func (b *PropertyBinding[T]) Get() T {
+	return b.get()
+}
+
+// Set changes the value of the property and pushes an undo command for it.
+// Changes made within bindingCoalesceThreshold of the previous change are merged
+// into the same command (e.g. while dragging a slider) as long as no other
+// command was pushed in between. Setting the current value does nothing.
+func (b *PropertyBinding[T]) Set(v T) {
+	oldValue := b.get()
+	if oldValue == v {
+		return
+	}
+	b.set(v)
+
+	now := time.Now()
+	if b.lastCmd != nil && b.history.Top() == Command(b.lastCmd) && now.Sub(b.lastSet) < bindingCoalesceThreshold {
+		b.lastCmd.newValue = v
+	} else {
+		b.lastCmd = &propertyCommand[T]{set: b.set, oldValue: oldValue, newValue: v}
+		b.history.Push(b.lastCmd)
+	}
+	b.lastSet = now
+}
@@ -0,0 +1,147 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+
+	fizzle "github.com/tbogdala/fizzle"
+)
+
+func TestUndoHistory(t *testing.T) {
+	value := 0
+	history := newUndoHistory()
+	history.Execute(&propertyCommand[int]{set: func(v int) { value = v }, oldValue: 0, newValue: 1})
+	history.Execute(&propertyCommand[int]{set: func(v int) { value = v }, oldValue: 1, newValue: 2})
+	if value != 2 {
+		t.Fatalf("Execute should apply the command; value is %d", value)
+	}
+
+	if !history.Undo() || value != 1 {
+		t.Errorf("Undo should revert the last command; value is %d", value)
+	}
+	if !history.Redo() || value != 2 {
+		t.Errorf("Redo should reapply the command; value is %d", value)
+	}
+
+	history.Undo()
+	history.Push(&propertyCommand[int]{set: func(v int) { value = v }, oldValue: 1, newValue: 3})
+	if history.Redo() {
+		t.Errorf("Pushing a new command should clear the redo stack.")
+	}
+
+	history.Clear()
+	if history.Undo() || history.Top() != nil {
+		t.Errorf("Clear should remove all of the commands.")
+	}
+}
+
+func TestPropertyBindingCoalescing(t *testing.T) {
+	value := float32(0.0)
+	history := newUndoHistory()
+	b := NewPropertyBinding(history, func() float32 { return value }, func(v float32) { value = v })
+
+	// rapid changes are merged into one command
+	b.Set(1.0)
+	b.Set(2.0)
+	b.Set(3.0)
+	if len(history.done) != 1 {
+		t.Fatalf("Rapid changes should be coalesced; got %d commands", len(history.done))
+	}
+
+	// setting the same value does nothing
+	b.Set(3.0)
+	if len(history.done) != 1 {
+		t.Errorf("Setting the current value should not push a command.")
+	}
+
+	// changes after the threshold start a new command
+	b.lastSet = b.lastSet.Add(-2 * bindingCoalesceThreshold)
+	b.Set(4.0)
+	if len(history.done) != 2 {
+		t.Fatalf("A change after the threshold should push a new command; got %d commands", len(history.done))
+	}
+
+	// another command in between also starts a new command
+	history.Push(&propertyCommand[int]{set: func(int) {}})
+	b.Set(5.0)
+	if len(history.done) != 4 {
+		t.Fatalf("A change after another command should push a new command; got %d commands", len(history.done))
+	}
+
+	history.Undo()
+	history.Undo()
+	history.Undo()
+	if value != 3.0 {
+		t.Errorf("Undoing the second binding command should restore 3.0; value is %f", value)
+	}
+	history.Undo()
+	if value != 0.0 {
+		t.Errorf("Undoing the coalesced command should restore 0.0; value is %f", value)
+	}
+}
+
+func TestCameraMoveTracker(t *testing.T) {
+	history := newUndoHistory()
+	tracker := newCameraMoveTracker(history)
+	camera := fizzle.NewOrbitCamera(mgl.Vec3{0, 0, 0}, 1.0, 5.0, 0.0)
+	start := camera.SaveCameraState()
+
+	// moves within the throttle time of the first are merged into one command
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		before := camera.SaveCameraState()
+		camera.Rotate(0.1)
+		tracker.Moved(camera, before, now.Add(time.Duration(i)*100*time.Millisecond))
+	}
+	afterFirstDrag := camera.SaveCameraState()
+
+	// not moving doesn't push anything
+	tracker.Moved(camera, camera.SaveCameraState(), now.Add(300*time.Millisecond))
+
+	// a new drag always starts a new command
+	tracker.EndDrag()
+	before := camera.SaveCameraState()
+	camera.SetDistance(10.0)
+	tracker.Moved(camera, before, now.Add(400*time.Millisecond))
+
+	// so does dragging past the throttle time
+	before = camera.SaveCameraState()
+	camera.Rotate(0.1)
+	tracker.Moved(camera, before, now.Add(400*time.Millisecond+cameraMoveThrottle))
+
+	history.Undo()
+	history.Undo()
+	if camera.SaveCameraState() != afterFirstDrag {
+		t.Errorf("Undoing the last two moves didn't return the camera to the end of the first drag.")
+	}
+	history.Undo()
+	if camera.SaveCameraState() != start {
+		t.Errorf("Undoing the first drag didn't return the camera to where it started.")
+	}
+	if history.Undo() {
+		t.Errorf("Only three camera commands should have been pushed.")
+	}
+}
+
+func TestCameraHistoryKeepsEditRedo(t *testing.T) {
+	value := 0
+	edits := newUndoHistory()
+	edits.Execute(&propertyCommand[int]{set: func(v int) { value = v }, oldValue: 0, newValue: 1})
+	edits.Undo()
+
+	cameras := newUndoHistory()
+	tracker := newCameraMoveTracker(cameras)
+	camera := fizzle.NewOrbitCamera(mgl.Vec3{0, 0, 0}, 1.0, 5.0, 0.0)
+	before := camera.SaveCameraState()
+	camera.Rotate(0.5)
+	tracker.Moved(camera, before, time.Now())
+
+	if !edits.Redo() || value != 1 {
+		t.Errorf("Moving the camera cleared the edit that could be redone.")
+	}
+}
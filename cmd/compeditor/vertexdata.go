@@ -0,0 +1,392 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	gui "github.com/tbogdala/eweygewey"
+
+	fizzle "github.com/tbogdala/fizzle"
+	component "github.com/tbogdala/fizzle/component"
+	graphics "github.com/tbogdala/fizzle/graphicsprovider"
+)
+
+const (
+	vertexDataWindowID = "VertexData"
+
+	// vertexDataPageSize is the number of vertex rows shown at first and the
+	// number of rows added every time "Load More" is pressed.
+	vertexDataPageSize = 256
+
+	// vertexDataColWidth is the width of each column in the vertex table.
+	vertexDataColWidth = 0.1
+)
+
+// vertexField identifies one of the editable values of a vertex.
+type vertexField int
+
+const (
+	vertexFieldX vertexField = iota
+	vertexFieldY
+	vertexFieldZ
+	vertexFieldNX
+	vertexFieldNY
+	vertexFieldNZ
+	vertexFieldU
+	vertexFieldV
+	vertexFieldCount
+)
+
+// vertexFieldNames are the column headers for the vertex fields.
+var vertexFieldNames = [vertexFieldCount]string{"X", "Y", "Z", "NX", "NY", "NZ", "U", "V"}
+
+// vertexCell is the key for one editable cell in the vertex table.
+type vertexCell struct {
+	index int
+	field vertexField
+}
+
+// vertexUpload identifies the attribute of a vertex in a mesh that needs to be
+// copied to the renderable's buffers. The field of the cell is the first field
+// of the attribute (X, NX or U).
+type vertexUpload struct {
+	mesh *component.Mesh
+	cell vertexCell
+}
+
+// vertexCellEditor keeps the text typed into a cell and binds the cell to the
+// undo history.
+type vertexCellEditor struct {
+	text    string
+	binding *PropertyBinding[float32]
+}
+
+// vertexDataPanel is the state for the vertex data window.
+type vertexDataPanel struct {
+	// mesh is the component mesh being shown. Changing the active mesh
+	// resets the panel.
+	mesh *component.Mesh
+
+	// rowLimit is the number of vertices shown in the table.
+	rowLimit int
+
+	// cells are the editors for the cells that have been shown and editing
+	// is the cell being typed in, whose text is kept until the edit is
+	// committed even if it can't be parsed yet (e.g. just a "-").
+	cells   map[vertexCell]*vertexCellEditor
+	editing vertexCell
+
+	// pendingUploads has the vertex attributes that changed so that they
+	// get copied to the renderable buffers before the next frame is drawn.
+	pendingUploads map[vertexUpload]bool
+}
+
+// vertexData is the state of the vertex data window.
+var vertexData = newVertexDataPanel()
+
+// newVertexDataPanel creates the panel state with nothing shown.
+func newVertexDataPanel() *vertexDataPanel {
+	p := new(vertexDataPanel)
+	p.pendingUploads = make(map[vertexUpload]bool)
+	p.reset(nil)
+	return p
+}
+
+// reset clears the panel to show the first page of the mesh's vertices. A
+// cell still being edited is committed first.
+func (p *vertexDataPanel) reset(compMesh *component.Mesh) {
+	p.commitEdit()
+	p.mesh = compMesh
+	p.rowLimit = vertexDataPageSize
+	p.cells = make(map[vertexCell]*vertexCellEditor)
+	p.editing = vertexCell{-1, 0}
+}
+
+// rowCount returns the number of vertex rows to show for a mesh with the
+// number of vertices specified.
+func (p *vertexDataPanel) rowCount(vertexCount int) int {
+	if vertexCount < p.rowLimit {
+		return vertexCount
+	}
+	return p.rowLimit
+}
+
+// loadMore adds another page of rows to the table.
+func (p *vertexDataPanel) loadMore() {
+	p.rowLimit += vertexDataPageSize
+}
+
+// getCellEditor returns the editor for the cell, creating it if needed.
+func (p *vertexDataPanel) getCellEditor(cell vertexCell) *vertexCellEditor {
+	editor, okay := p.cells[cell]
+	if !okay {
+		compMesh := p.mesh
+		editor = new(vertexCellEditor)
+		editor.binding = NewPropertyBinding(editHistory,
+			func() float32 { return getVertexField(compMesh, cell) },
+			func(v float32) {
+				setVertexField(compMesh, cell, v)
+				p.pendingUploads[vertexUpload{compMesh, vertexCell{cell.index, getAttributeField(cell.field)}}] = true
+			})
+		editor.text = formatVertexValue(editor.binding.Get())
+		p.cells[cell] = editor
+	}
+	return editor
+}
+
+// editCell keeps the text typed into the cell. Starting to type in a different
+// cell commits the edit of the previous one.
+func (p *vertexDataPanel) editCell(cell vertexCell, text string) {
+	if cell != p.editing {
+		p.commitEdit()
+		p.editing = cell
+	}
+	p.getCellEditor(cell).text = text
+}
+
+// commitEdit sets the vertex field to the value typed into the cell being
+// edited, which pushes it to the undo history and queues the attribute to be
+// uploaded. Text that doesn't parse as a number is dropped and the cell shows
+// the current value again.
+func (p *vertexDataPanel) commitEdit() {
+	if p.editing.index < 0 {
+		return
+	}
+
+	editor, okay := p.cells[p.editing]
+	p.editing = vertexCell{-1, 0}
+	if !okay {
+		return
+	}
+	if v, err := strconv.ParseFloat(editor.text, 32); err == nil {
+		editor.binding.Set(float32(v))
+	}
+	editor.text = formatVertexValue(editor.binding.Get())
+}
+
+// getVertexField returns the value of the vertex field from the mesh data.
+func getVertexField(compMesh *component.Mesh, cell vertexCell) float32 {
+	srcMesh := compMesh.SrcMesh
+	switch {
+	case cell.field <= vertexFieldZ:
+		return srcMesh.Vertices[cell.index][cell.field-vertexFieldX]
+	case cell.field <= vertexFieldNZ:
+		return srcMesh.Normals[cell.index][cell.field-vertexFieldNX]
+	default:
+		return srcMesh.UVChannels[0][cell.index][cell.field-vertexFieldU]
+	}
+}
+
+// setVertexField changes the value of the vertex field in the mesh data.
+func setVertexField(compMesh *component.Mesh, cell vertexCell, v float32) {
+	srcMesh := compMesh.SrcMesh
+	switch {
+	case cell.field <= vertexFieldZ:
+		srcMesh.Vertices[cell.index][cell.field-vertexFieldX] = v
+	case cell.field <= vertexFieldNZ:
+		srcMesh.Normals[cell.index][cell.field-vertexFieldNX] = v
+	default:
+		srcMesh.UVChannels[0][cell.index][cell.field-vertexFieldU] = v
+	}
+}
+
+// getAttributeField returns the first field of the vertex attribute that the
+// field belongs to: vertexFieldX, vertexFieldNX or vertexFieldU.
+func getAttributeField(field vertexField) vertexField {
+	switch {
+	case field <= vertexFieldZ:
+		return vertexFieldX
+	case field <= vertexFieldNZ:
+		return vertexFieldNX
+	default:
+		return vertexFieldU
+	}
+}
+
+// hasVertexField returns true if the mesh data has the field for the vertex.
+func hasVertexField(compMesh *component.Mesh, cell vertexCell) bool {
+	srcMesh := compMesh.SrcMesh
+	switch {
+	case cell.field <= vertexFieldZ:
+		return cell.index < len(srcMesh.Vertices)
+	case cell.field <= vertexFieldNZ:
+		return cell.index < len(srcMesh.Normals)
+	default:
+		return len(srcMesh.UVChannels) > 0 && cell.index < len(srcMesh.UVChannels[0])
+	}
+}
+
+// formatVertexValue returns the text shown for a vertex value.
+func formatVertexValue(v float32) string {
+	return strconv.FormatFloat(float64(v), 'f', -1, 32)
+}
+
+// doToggleVertexDataWindow shows the vertex data window if it's hidden and
+// hides it if it's showing.
+func doToggleVertexDataWindow() {
+	vertexWindow := uiman.GetWindow(vertexDataWindowID)
+	if vertexWindow != nil {
+		uiman.RemoveWindow(vertexWindow)
+		return
+	}
+
+	vertexWindow = uiman.NewWindow(vertexDataWindowID, 0.27, 0.60, 0.45, 0.35, func(wnd *gui.Window) {
+		renderVertexDataPanel(wnd)
+	})
+	vertexWindow.Title = "Vertex Data"
+	vertexWindow.ShowTitleBar = true
+	vertexWindow.IsMoveable = true
+	vertexWindow.IsScrollable = true
+	vertexWindow.ShowScrollBar = true
+	vertexWindow.AutoAdjustHeight = false
+}
+
+// renderVertexDataPanel shows a table of the position, normal and texture
+// coordinate of each vertex in the active mesh. Every value can be edited and
+// changes get pushed to the undo history.
+func renderVertexDataPanel(wnd *gui.Window) {
+	if activeMesh == nil || activeMesh.SrcMesh == nil {
+		vertexData.reset(nil)
+		wnd.Text("Select a mesh with loaded data to edit its vertices.")
+		return
+	}
+	if vertexData.mesh != activeMesh {
+		vertexData.reset(activeMesh)
+	}
+
+	vertexCount := len(activeMesh.SrcMesh.Vertices)
+	rowCount := vertexData.rowCount(vertexCount)
+	wnd.Text(fmt.Sprintf("%s: showing %d of %d vertices", activeMesh.Name, rowCount, vertexCount))
+	if rowCount < vertexCount {
+		loadMore, _ := wnd.Button("vertexDataLoadMoreButton", "Load More")
+		if loadMore {
+			vertexData.loadMore()
+		}
+	}
+
+	wnd.Separator()
+	wnd.RequestItemWidthMin(vertexDataColWidth)
+	wnd.Text("Index")
+	for _, name := range vertexFieldNames {
+		wnd.RequestItemWidthMin(vertexDataColWidth)
+		wnd.Text(name)
+	}
+
+	for i := 0; i < rowCount; i++ {
+		wnd.StartRow()
+		wnd.RequestItemWidthMin(vertexDataColWidth)
+		wnd.Text(fmt.Sprintf("%d", i))
+
+		for field := vertexFieldX; field < vertexFieldCount; field++ {
+			cell := vertexCell{i, field}
+			wnd.RequestItemWidthMin(vertexDataColWidth)
+			wnd.RequestItemWidthMax(vertexDataColWidth)
+			if !hasVertexField(activeMesh, cell) {
+				wnd.Text("-")
+				continue
+			}
+			guiAddVertexCell(wnd, cell)
+		}
+	}
+}
+
+// guiAddVertexCell adds the editbox for a cell of the vertex table. The typed
+// text is kept while the cell has focus and the value is set once the cell
+// loses it or another cell gets typed in.
+func guiAddVertexCell(wnd *gui.Window, cell vertexCell) {
+	editor := vertexData.getCellEditor(cell)
+
+	// show the current value unless the cell is being typed in, such as
+	// after an undo changed the value
+	if cell != vertexData.editing {
+		editor.text = formatVertexValue(editor.binding.Get())
+	}
+
+	id := fmt.Sprintf("vertexDataCell%d_%d", cell.index, cell.field)
+	text := editor.text
+	wnd.Editbox(id, &text)
+	if text != editor.text {
+		vertexData.editCell(cell, text)
+	}
+
+	if cell == vertexData.editing && uiman.GetActiveInputID() != id {
+		vertexData.commitEdit()
+	}
+}
+
+// uploadEditedVertexData copies the edited vertex attributes into the buffers of
+// the mesh renderables so that the changes show up in the next frame. Only the
+// changed vertices are written, so the renderables keep their animation state.
+// Meshes that are no longer part of the component, such as after an undo of
+// an edit to a mesh that has since been removed, are skipped.
+func uploadEditedVertexData(gfx graphics.GraphicsProvider) {
+	if len(vertexData.pendingUploads) == 0 {
+		return
+	}
+
+	movedMeshes := make(map[*component.Mesh]*fizzle.Renderable)
+	for upload := range vertexData.pendingUploads {
+		delete(vertexData.pendingUploads, upload)
+
+		compMesh := upload.mesh
+		if !isMeshInComponent(&theComponent, compMesh) || compMesh.SrcMesh == nil {
+			continue
+		}
+		visible, okay := visibleMeshes[compMesh.Name]
+		if !okay || visible.ComponentMesh != compMesh || visible.Renderable == nil {
+			continue
+		}
+
+		srcMesh := compMesh.SrcMesh
+		core := visible.Renderable.Core
+		i := upload.cell.index
+		switch upload.cell.field {
+		case vertexFieldX:
+			v := srcMesh.Vertices[i]
+			uploadVertexAttribute(gfx, core.VertVBO, i, v[:])
+			movedMeshes[compMesh] = visible.Renderable
+		case vertexFieldNX:
+			n := srcMesh.Normals[i]
+			uploadVertexAttribute(gfx, core.NormsVBO, i, n[:])
+		case vertexFieldU:
+			uv := srcMesh.UVChannels[0][i]
+			uploadVertexAttribute(gfx, core.UvVBO, i, uv[:])
+		}
+	}
+
+	// moving vertices changes the bounds of the mesh
+	for compMesh, r := range movedMeshes {
+		verts := make([]float32, 0, len(compMesh.SrcMesh.Vertices)*3)
+		for _, v := range compMesh.SrcMesh.Vertices {
+			verts = append(verts, v[0], v[1], v[2])
+		}
+		r.BoundingRect = fizzle.GetBoundingRect(verts)
+	}
+}
+
+// uploadVertexAttribute writes the values of one vertex attribute into the
+// buffer where the attribute is tightly packed for every vertex.
+func uploadVertexAttribute(gfx graphics.GraphicsProvider, vbo graphics.Buffer, index int, values []float32) {
+	const floatSize = 4
+	if vbo == 0 {
+		return
+	}
+
+	size := floatSize * len(values)
+	gfx.BindBuffer(graphics.ARRAY_BUFFER, vbo)
+	gfx.BufferSubData(graphics.ARRAY_BUFFER, index*size, size, gfx.Ptr(&values[0]))
+	gfx.BindBuffer(graphics.ARRAY_BUFFER, 0)
+}
+
+// isMeshInComponent returns true if the mesh is one of the component's meshes.
+func isMeshInComponent(comp *component.Component, compMesh *component.Mesh) bool {
+	for _, m := range comp.Meshes {
+		if m == compMesh {
+			return true
+		}
+	}
+	return false
+}
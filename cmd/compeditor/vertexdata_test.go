@@ -0,0 +1,243 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package main
+
+import (
+	"testing"
+	"unsafe"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/tbogdala/gombz"
+
+	fizzle "github.com/tbogdala/fizzle"
+	component "github.com/tbogdala/fizzle/component"
+	graphics "github.com/tbogdala/fizzle/graphicsprovider"
+)
+
+func TestVertexDataPanelRowCount(t *testing.T) {
+	p := newVertexDataPanel()
+
+	tests := []struct {
+		loadMoreCount int
+		vertexCount   int
+		expected      int
+	}{
+		{0, 0, 0},
+		{0, 10, 10},
+		{0, vertexDataPageSize, vertexDataPageSize},
+		{0, 1000, vertexDataPageSize},
+		{1, 1000, 2 * vertexDataPageSize},
+		{1, 300, 300},
+		{3, 1000, 1000},
+	}
+
+	for _, test := range tests {
+		p.reset(nil)
+		for i := 0; i < test.loadMoreCount; i++ {
+			p.loadMore()
+		}
+		if count := p.rowCount(test.vertexCount); count != test.expected {
+			t.Errorf("rowCount(%d) after %d loadMore calls returned %d; expected %d",
+				test.vertexCount, test.loadMoreCount, count, test.expected)
+		}
+	}
+}
+
+func TestGetAttributeField(t *testing.T) {
+	expected := [vertexFieldCount]vertexField{
+		vertexFieldX, vertexFieldX, vertexFieldX,
+		vertexFieldNX, vertexFieldNX, vertexFieldNX,
+		vertexFieldU, vertexFieldU,
+	}
+	for field := vertexFieldX; field < vertexFieldCount; field++ {
+		if attrField := getAttributeField(field); attrField != expected[field] {
+			t.Errorf("getAttributeField(%s) returned %s; expected %s",
+				vertexFieldNames[field], vertexFieldNames[attrField], vertexFieldNames[expected[field]])
+		}
+	}
+}
+
+func TestIsMeshInComponent(t *testing.T) {
+	comp := new(component.Component)
+	kept := component.NewMesh()
+	removed := component.NewMesh()
+	comp.Meshes = []*component.Mesh{kept}
+
+	if !isMeshInComponent(comp, kept) {
+		t.Errorf("isMeshInComponent didn't find a mesh in the component.")
+	}
+	if isMeshInComponent(comp, removed) {
+		t.Errorf("isMeshInComponent found a mesh that isn't in the component.")
+	}
+}
+
+// mockBufferGraphics records the buffer updates made with BufferSubData.
+// Calling any GraphicsProvider method it doesn't implement panics.
+type mockBufferGraphics struct {
+	graphics.GraphicsProvider
+
+	bound   graphics.Buffer
+	updates []mockBufferUpdate
+}
+
+type mockBufferUpdate struct {
+	buffer graphics.Buffer
+	offset int
+	values []float32
+}
+
+func (g *mockBufferGraphics) BindBuffer(target graphics.Enum, b graphics.Buffer) { g.bound = b }
+func (g *mockBufferGraphics) Ptr(data interface{}) unsafe.Pointer {
+	return unsafe.Pointer(data.(*float32))
+}
+
+func (g *mockBufferGraphics) BufferSubData(target graphics.Enum, offset int, size int, data unsafe.Pointer) {
+	var values []float32
+	for i := 0; i < size/4; i++ {
+		values = append(values, *(*float32)(unsafe.Pointer(uintptr(data) + uintptr(i*4))))
+	}
+	g.updates = append(g.updates, mockBufferUpdate{g.bound, offset, values})
+}
+
+func TestUploadVertexAttribute(t *testing.T) {
+	g := new(mockBufferGraphics)
+
+	uploadVertexAttribute(g, 7, 3, []float32{1, 2, 3})
+	uploadVertexAttribute(g, 8, 5, []float32{0.25, 0.75})
+	uploadVertexAttribute(g, 0, 1, []float32{4, 5, 6})
+
+	expected := []mockBufferUpdate{
+		{7, 3 * 3 * 4, []float32{1, 2, 3}},
+		{8, 5 * 2 * 4, []float32{0.25, 0.75}},
+	}
+	if len(g.updates) != len(expected) {
+		t.Fatalf("uploadVertexAttribute made %d buffer updates; expected %d", len(g.updates), len(expected))
+	}
+	for i, update := range g.updates {
+		e := expected[i]
+		if update.buffer != e.buffer || update.offset != e.offset || len(update.values) != len(e.values) {
+			t.Errorf("Buffer update %d was %v; expected %v", i, update, e)
+			continue
+		}
+		for j := range e.values {
+			if update.values[j] != e.values[j] {
+				t.Errorf("Buffer update %d wrote %v; expected %v", i, update.values, e.values)
+				break
+			}
+		}
+	}
+	if g.bound != 0 {
+		t.Errorf("uploadVertexAttribute left buffer %d bound", g.bound)
+	}
+}
+
+// createVertexTestMesh returns a component mesh with four vertices that each
+// have a position, normal and texture coordinate.
+func createVertexTestMesh(name string) *component.Mesh {
+	compMesh := component.NewMesh()
+	compMesh.Name = name
+	compMesh.SrcMesh = new(gombz.Mesh)
+	compMesh.SrcMesh.Vertices = []mgl.Vec3{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}}
+	compMesh.SrcMesh.Normals = []mgl.Vec3{{0, 0, 1}, {0, 0, 1}, {0, 0, 1}, {0, 0, 1}}
+	compMesh.SrcMesh.UVChannels = [][]mgl.Vec2{{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}
+	return compMesh
+}
+
+func TestVertexDataPanelCommitEdit(t *testing.T) {
+	editHistory = newUndoHistory()
+	compMesh := createVertexTestMesh("quad")
+	p := newVertexDataPanel()
+	p.reset(compMesh)
+
+	// typing doesn't change anything until the edit is committed
+	cell := vertexCell{2, vertexFieldY}
+	p.editCell(cell, "-")
+	p.editCell(cell, "-1.5")
+	if v := getVertexField(compMesh, cell); v != 1.0 || len(p.pendingUploads) != 0 || editHistory.Top() != nil {
+		t.Fatalf("Typing in a cell changed the value to %f and queued %d uploads before the edit was committed", v, len(p.pendingUploads))
+	}
+
+	p.commitEdit()
+	if v := getVertexField(compMesh, cell); v != -1.5 {
+		t.Errorf("Committing the edit set the value to %f; expected -1.5", v)
+	}
+	expected := vertexUpload{compMesh, vertexCell{2, vertexFieldX}}
+	if len(p.pendingUploads) != 1 || !p.pendingUploads[expected] {
+		t.Errorf("Committing the edit queued %v; expected only %v", p.pendingUploads, expected)
+	}
+	if editHistory.Top() == nil {
+		t.Errorf("Committing the edit didn't push it to the undo history.")
+	}
+
+	// text that doesn't parse is dropped when typing moves to another cell
+	delete(p.pendingUploads, expected)
+	badCell := vertexCell{1, vertexFieldU}
+	p.editCell(badCell, "abc")
+	p.editCell(vertexCell{0, vertexFieldNZ}, "0.5")
+	if len(p.pendingUploads) != 0 || p.cells[badCell].text != "1" || getVertexField(compMesh, badCell) != 1.0 {
+		t.Errorf("Committing unparsable text queued %v and left the text %q", p.pendingUploads, p.cells[badCell].text)
+	}
+
+	// changing the mesh commits the cell being edited
+	p.reset(nil)
+	expected = vertexUpload{compMesh, vertexCell{0, vertexFieldNX}}
+	if len(p.pendingUploads) != 1 || !p.pendingUploads[expected] || compMesh.SrcMesh.Normals[0][2] != 0.5 {
+		t.Errorf("Resetting the panel queued %v; expected only %v", p.pendingUploads, expected)
+	}
+}
+
+func TestUploadEditedVertexData(t *testing.T) {
+	compMesh := createVertexTestMesh("quad")
+	removedMesh := createVertexTestMesh("removed")
+	r := &fizzle.Renderable{Core: &fizzle.RenderableCore{VertVBO: 7, NormsVBO: 8, UvVBO: 9}}
+	theComponent = component.Component{Meshes: []*component.Mesh{compMesh}}
+	visibleMeshes = map[string]*meshRenderable{
+		"quad":    {ComponentMesh: compMesh, Renderable: r},
+		"removed": {ComponentMesh: removedMesh, Renderable: r},
+	}
+	defer func() {
+		theComponent = component.Component{}
+		visibleMeshes = nil
+		vertexData = newVertexDataPanel()
+	}()
+
+	compMesh.SrcMesh.Vertices[1] = mgl.Vec3{2, 3, 4}
+	compMesh.SrcMesh.UVChannels[0][3] = mgl.Vec2{0.5, 0.25}
+	vertexData = newVertexDataPanel()
+	vertexData.pendingUploads[vertexUpload{compMesh, vertexCell{1, vertexFieldX}}] = true
+	vertexData.pendingUploads[vertexUpload{compMesh, vertexCell{3, vertexFieldU}}] = true
+	vertexData.pendingUploads[vertexUpload{removedMesh, vertexCell{0, vertexFieldNX}}] = true
+
+	g := new(mockBufferGraphics)
+	uploadEditedVertexData(g)
+
+	expected := map[graphics.Buffer]mockBufferUpdate{
+		7: {7, 1 * 3 * 4, []float32{2, 3, 4}},
+		9: {9, 3 * 2 * 4, []float32{0.5, 0.25}},
+	}
+	if len(g.updates) != len(expected) {
+		t.Fatalf("uploadEditedVertexData made the buffer updates %v; expected %v", g.updates, expected)
+	}
+	for _, update := range g.updates {
+		e, okay := expected[update.buffer]
+		if !okay || update.offset != e.offset || len(update.values) != len(e.values) {
+			t.Errorf("uploadEditedVertexData made the buffer update %v; expected one of %v", update, expected)
+			continue
+		}
+		for j := range e.values {
+			if update.values[j] != e.values[j] {
+				t.Errorf("Buffer update %v wrote %v; expected %v", update, update.values, e.values)
+				break
+			}
+		}
+		delete(expected, update.buffer)
+	}
+
+	if len(vertexData.pendingUploads) != 0 {
+		t.Errorf("uploadEditedVertexData left %d uploads queued", len(vertexData.pendingUploads))
+	}
+	if r.BoundingRect.Top != (mgl.Vec3{2, 3, 4}) {
+		t.Errorf("The renderable bounds top is %v after moving a vertex; expected %v", r.BoundingRect.Top, mgl.Vec3{2, 3, 4})
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+// AnimationEvent defines a named event that should be triggered when playback
+// of an animation clip reaches a given frame. This allows client code to hook
+// game logic, such as footstep sounds, to specific points in an animation.
+type AnimationEvent struct {
+	// ClipName is the name of the animation the event belongs to.
+	ClipName string
+
+	// Frame is the frame (animation tick) of the clip that triggers the event.
+	Frame int
+
+	// EventName is the user identifier for the event.
+	EventName string
+
+	// Data is a map for client code's custom data for the event.
+	Data map[string]interface{}
+}
+
+// AnimationEventHandler is the type of function called when an AnimationEvent
+// has been triggered for a component.
+type AnimationEventHandler func(component *Component, event AnimationEvent)
+
+// FireAnimationEvents calls the handler for every AnimationEvent in the component
+// for the clip specified whose frame was crossed when playback moved from
+// prevFrame to curFrame. clipLength is the number of frames in the clip and is
+// used to detect playback wrapping back around to the start of the clip.
+//
+// A prevFrame of -1 should be used when playback is just starting so that events
+// on frame 0 are triggered. Events placed at or beyond clipLength never fire.
+func (c *Component) FireAnimationEvents(clipName string, prevFrame, curFrame, clipLength int, handler AnimationEventHandler) {
+	if handler == nil || prevFrame == curFrame {
+		return
+	}
+
+	for _, event := range c.AnimationEvents {
+		if event.ClipName != clipName || event.Frame < 0 || event.Frame >= clipLength {
+			continue
+		}
+
+		if animationFrameCrossed(event.Frame, prevFrame, curFrame) {
+			handler(c, event)
+		}
+	}
+}
+
+// animationFrameCrossed returns true if the frame lies within (prevFrame, curFrame],
+// taking into account playback that has looped back to the start of the clip.
+func animationFrameCrossed(frame, prevFrame, curFrame int) bool {
+	if curFrame > prevFrame {
+		return frame > prevFrame && frame <= curFrame
+	}
+
+	// playback has wrapped around to the start of the clip
+	return frame > prevFrame || frame <= curFrame
+}
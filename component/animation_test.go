@@ -0,0 +1,68 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import "testing"
+
+func TestAnimationFrameCrossed(t *testing.T) {
+	tests := []struct {
+		frame, prevFrame, curFrame int
+		expected                   bool
+	}{
+		{5, 4, 5, true},
+		{5, 5, 6, false},
+		{5, 2, 8, true},
+		{5, 6, 8, false},
+		{0, -1, 0, true},
+		{1, 8, 2, true},  // wrapped past the end of the clip
+		{9, 8, 2, true},  // wrapped after the event near the end
+		{5, 8, 2, false}, // wrapped but the event was skipped over before
+	}
+
+	for _, test := range tests {
+		result := animationFrameCrossed(test.frame, test.prevFrame, test.curFrame)
+		if result != test.expected {
+			t.Errorf("animationFrameCrossed(%d, %d, %d) returned %v; expected %v",
+				test.frame, test.prevFrame, test.curFrame, result, test.expected)
+		}
+	}
+}
+
+func TestFireAnimationEvents(t *testing.T) {
+	c := new(Component)
+	c.AnimationEvents = []AnimationEvent{
+		{ClipName: "walk", Frame: 0, EventName: "start"},
+		{ClipName: "walk", Frame: 4, EventName: "step"},
+		{ClipName: "run", Frame: 4, EventName: "other clip"},
+		{ClipName: "walk", Frame: 10, EventName: "past the end"},
+	}
+
+	var fired []string
+	handler := func(comp *Component, event AnimationEvent) {
+		if comp != c {
+			t.Errorf("The handler was called with the wrong component.")
+		}
+		fired = append(fired, event.EventName)
+	}
+
+	c.FireAnimationEvents("walk", -1, 0, 10, handler)
+	c.FireAnimationEvents("walk", 0, 3, 10, handler)
+	c.FireAnimationEvents("walk", 3, 3, 10, handler)
+	c.FireAnimationEvents("walk", 3, 5, 10, handler)
+	c.FireAnimationEvents("walk", 9, 1, 10, handler)
+
+	expected := []string{"start", "step", "start"}
+	if len(fired) != len(expected) {
+		t.Fatalf("Fired events %v; expected %v", fired, expected)
+	}
+	for i := range expected {
+		if fired[i] != expected[i] {
+			t.Errorf("Fired events %v; expected %v", fired, expected)
+			break
+		}
+	}
+
+	// a nil handler should be ignored
+	c.FireAnimationEvents("walk", -1, 5, 10, nil)
+}
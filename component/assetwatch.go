@@ -0,0 +1,181 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tbogdala/fizzle"
+	"github.com/tbogdala/groggy"
+)
+
+const (
+	// AssetTypeComponent is the AssetFileInfo type for component files.
+	AssetTypeComponent = "component"
+
+	// AssetTypeMesh is the AssetFileInfo type for the mesh binary files of a component.
+	AssetTypeMesh = "mesh"
+
+	// AssetTypeTexture is the AssetFileInfo type for texture files.
+	AssetTypeTexture = "texture"
+)
+
+// AssetFileInfo describes a file loaded by the Manager.
+type AssetFileInfo struct {
+	// Type is the kind of asset in the file (e.g. AssetTypeTexture).
+	Type string
+
+	// StorageName is the name of the component or texture that was loaded
+	// from the file. Mesh files use the name of their component.
+	StorageName string
+
+	// Path is the file path that was loaded.
+	Path string
+
+	// LoadedTime is when the file was loaded.
+	LoadedTime time.Time
+
+	// ModifiedTime is the last modification time of the file on disk when
+	// GetLoadedFilePaths was called. It is zero if the file can't be found.
+	ModifiedTime time.Time
+}
+
+// IsStale returns true if the file on disk was modified after it was loaded.
+func (info AssetFileInfo) IsStale() bool {
+	return info.ModifiedTime.After(info.LoadedTime)
+}
+
+// RecordLoadedFile remembers that the file was loaded for the asset so that
+// it shows up in GetLoadedFilePaths. Files loaded outside of the Manager, such
+// as by an editor, can be recorded with this to have them watched too.
+func (cm *Manager) RecordLoadedFile(assetType, storageName, path string) {
+	cm.loadedFiles[path] = AssetFileInfo{
+		Type:        assetType,
+		StorageName: storageName,
+		Path:        path,
+		LoadedTime:  time.Now(),
+	}
+}
+
+// ForgetLoadedComponentFiles removes the component and mesh files recorded for
+// the component storage name.
+func (cm *Manager) ForgetLoadedComponentFiles(storageName string) {
+	for path, info := range cm.loadedFiles {
+		if info.Type != AssetTypeTexture && info.StorageName == storageName {
+			delete(cm.loadedFiles, path)
+		}
+	}
+}
+
+// getLoadedComponentFiles returns a copy of the component and mesh files
+// recorded for the component storage name indexed by file path.
+func (cm *Manager) getLoadedComponentFiles(storageName string) map[string]AssetFileInfo {
+	files := make(map[string]AssetFileInfo)
+	for path, info := range cm.loadedFiles {
+		if info.Type != AssetTypeTexture && info.StorageName == storageName {
+			files[path] = info
+		}
+	}
+	return files
+}
+
+// GetLoadedFilePaths returns information about every file loaded by the Manager
+// indexed by file path, including the current modification time of each file.
+func (cm *Manager) GetLoadedFilePaths() map[string]AssetFileInfo {
+	result := make(map[string]AssetFileInfo, len(cm.loadedFiles))
+	for path, info := range cm.loadedFiles {
+		if stat, err := os.Stat(path); err == nil {
+			info.ModifiedTime = stat.ModTime()
+		}
+		result[path] = info
+	}
+	return result
+}
+
+// ReloadStaleFiles reloads every component and texture whose file has been
+// modified since it was loaded. Components with stale mesh files are reloaded
+// as well. Textures are reloaded in place so that every renderable using them
+// shows the new image. Renderables that were already created from the old
+// components keep using the old mesh data and need to be recreated; the old
+// data is left for the owners of those renderables to destroy. Returns the
+// number of assets reloaded and any errors that happened.
+func (cm *Manager) ReloadStaleFiles() (int, []error) {
+	reloadedCount := 0
+	var reloadErrors []error
+
+	componentsToReload := make(map[string]string)
+	for path, info := range cm.GetLoadedFilePaths() {
+		if !info.IsStale() {
+			continue
+		}
+
+		switch info.Type {
+		case AssetTypeTexture:
+			err := cm.textureManager.ReloadTexture(info.StorageName, path)
+			if err != nil {
+				reloadErrors = append(reloadErrors, fmt.Errorf("Failed to reload texture %s.\n%v\n", info.StorageName, err))
+				continue
+			}
+			if cm.textureUsesMipmaps(info.StorageName) {
+				if glTexture, okay := cm.textureManager.GetTexture(info.StorageName); okay {
+					fizzle.GenerateMipmaps(glTexture)
+				}
+			}
+			cm.RecordLoadedFile(AssetTypeTexture, info.StorageName, path)
+			reloadedCount++
+		default:
+			if component, okay := cm.storage[info.StorageName]; okay {
+				componentsToReload[info.StorageName] = component.componentFilePath
+			}
+		}
+	}
+
+	for storageName, componentPath := range componentsToReload {
+		if componentPath == "" {
+			continue
+		}
+
+		oldComponent := cm.storage[storageName]
+		oldFiles := cm.getLoadedComponentFiles(storageName)
+		delete(cm.storage, storageName)
+		cm.ForgetLoadedComponentFiles(storageName)
+		_, err := cm.LoadComponentFromFile(componentPath, storageName)
+		if err != nil {
+			// put the old component and its file records back so that it's
+			// still usable and the files are still reported as stale
+			cm.storage[storageName] = oldComponent
+			cm.ForgetLoadedComponentFiles(storageName)
+			for path, info := range oldFiles {
+				cm.loadedFiles[path] = info
+			}
+			reloadErrors = append(reloadErrors, fmt.Errorf("Failed to reload component %s.\n%v\n", storageName, err))
+			continue
+		}
+
+		groggy.Logsf("DEBUG", "Component \"%s\" has been reloaded", storageName)
+		reloadedCount++
+	}
+
+	return reloadedCount, reloadErrors
+}
+
+// textureUsesMipmaps returns true if a mesh material of any component in storage
+// uses the texture and generates mipmaps for it.
+func (cm *Manager) textureUsesMipmaps(textureName string) bool {
+	for _, component := range cm.storage {
+		for _, compMesh := range component.Meshes {
+			if !compMesh.Material.GenerateMipmaps {
+				continue
+			}
+			for _, texName := range getMaterialTextureNames(&compMesh.Material) {
+				if texName == textureName {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
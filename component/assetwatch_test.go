@@ -0,0 +1,113 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAssetFileInfoIsStale(t *testing.T) {
+	loaded := time.Date(2016, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		modified time.Time
+		expected bool
+	}{
+		{loaded.Add(time.Second), true},
+		{loaded, false},
+		{loaded.Add(-time.Second), false},
+		{time.Time{}, false},
+	}
+
+	for _, test := range tests {
+		info := AssetFileInfo{LoadedTime: loaded, ModifiedTime: test.modified}
+		if stale := info.IsStale(); stale != test.expected {
+			t.Errorf("IsStale with modified time %v returned %v; expected %v", test.modified, stale, test.expected)
+		}
+	}
+}
+
+func TestReloadStaleFilesKeepsOldComponent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fizzle_components")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestComponentFile(t, dir, "comp.json", "before")
+	compPath := filepath.Join(dir, "comp.json")
+	cm := NewManager(nil, nil)
+	oldComp, err := cm.LoadComponentFromFile(compPath, "comp")
+	if err != nil {
+		t.Fatalf("Failed to load the test component: %v", err)
+	}
+	oldMesh := NewMesh()
+	oldComp.Meshes = append(oldComp.Meshes, oldMesh)
+
+	// nothing is stale right after loading
+	if count, errs := cm.ReloadStaleFiles(); count != 0 || len(errs) != 0 {
+		t.Fatalf("ReloadStaleFiles reloaded %d assets with errors %v; expected none", count, errs)
+	}
+
+	writeTestComponentFile(t, dir, "comp.json", "after")
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(compPath, future, future)
+	if info := cm.GetLoadedFilePaths()[compPath]; !info.IsStale() {
+		t.Fatalf("The rewritten component file is not stale.")
+	}
+
+	count, errs := cm.ReloadStaleFiles()
+	if count != 1 || len(errs) != 0 {
+		t.Fatalf("ReloadStaleFiles reloaded %d assets with errors %v; expected 1 without errors", count, errs)
+	}
+	newComp, _ := cm.GetComponent("comp")
+	if newComp == oldComp || newComp.Name != "after" {
+		t.Errorf("The component was not reloaded from the file.")
+	}
+	if len(oldComp.Meshes) != 1 || oldComp.Meshes[0] != oldMesh || oldComp.Name != "before" {
+		t.Errorf("The old component was changed by the reload.")
+	}
+}
+
+func TestReloadStaleFilesFailureStaysStale(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fizzle_components")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestComponentFile(t, dir, "comp.json", "before")
+	compPath := filepath.Join(dir, "comp.json")
+	cm := NewManager(nil, nil)
+	oldComp, err := cm.LoadComponentFromFile(compPath, "comp")
+	if err != nil {
+		t.Fatalf("Failed to load the test component: %v", err)
+	}
+
+	err = ioutil.WriteFile(compPath, []byte("{ not json"), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write the broken component file: %v", err)
+	}
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(compPath, future, future)
+
+	// the failed reload should keep the old component and leave the file
+	// stale so that the next call tries again
+	for i := 0; i < 2; i++ {
+		count, errs := cm.ReloadStaleFiles()
+		if count != 0 || len(errs) != 1 {
+			t.Fatalf("ReloadStaleFiles reloaded %d assets with errors %v; expected 0 with one error", count, errs)
+		}
+		if comp, _ := cm.GetComponent("comp"); comp != oldComp {
+			t.Errorf("The old component was not kept after the failed reload.")
+		}
+		info, okay := cm.GetLoadedFilePaths()[compPath]
+		if !okay || !info.IsStale() {
+			t.Errorf("The component file is not reported as stale after the failed reload.")
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/tbogdala/groggy"
+)
+
+const (
+	// AuditOpAdd is logged when a component is added with AddComponent().
+	AuditOpAdd = "add"
+
+	// AuditOpRemove is logged when a component is removed with RemoveComponent().
+	AuditOpRemove = "remove"
+
+	// AuditOpSave is logged when a component is saved with SaveComponentToFile().
+	AuditOpSave = "save"
+
+	// AuditOpRename is logged when a component is renamed with RenameComponent().
+	AuditOpRename = "rename"
+)
+
+// AuditLogEntry is a single line of the audit log written by a Manager.
+type AuditLogEntry struct {
+	// Time is when the operation happened.
+	Time time.Time `json:"time"`
+
+	// Op is the operation performed (e.g. AuditOpAdd).
+	Op string `json:"op"`
+
+	// Name is the storage name of the component operated on.
+	Name string `json:"name"`
+
+	// NewName is the storage name a component was renamed to.
+	NewName string `json:"new_name,omitempty"`
+
+	// Path is the file a component was saved to, or for added components
+	// the file it was originally loaded from, if known.
+	Path string `json:"path,omitempty"`
+
+	// User is the name of the user that performed the operation.
+	User string `json:"user"`
+
+	// Hostname is the name of the machine the operation was performed on.
+	Hostname string `json:"hostname"`
+}
+
+// EnableAuditLog opens the file at logPath for appending and logs every
+// AddComponent, RemoveComponent, SaveComponentToFile and RenameComponent call
+// to it as a line of JSON. A previously enabled audit log is closed first.
+func (cm *Manager) EnableAuditLog(logPath string) error {
+	cm.DisableAuditLog()
+
+	f, err := os.OpenFile(logPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("Failed to open the audit log %s.\n%v\n", logPath, err)
+	}
+	cm.auditLog = f
+
+	// look these up once instead of for every entry written
+	if u, err := user.Current(); err == nil {
+		cm.auditUser = u.Username
+	} else {
+		cm.auditUser = os.Getenv("USER")
+	}
+	cm.auditHostname, _ = os.Hostname()
+
+	return nil
+}
+
+// DisableAuditLog closes the audit log, if one is enabled.
+func (cm *Manager) DisableAuditLog() {
+	if cm.auditLog == nil {
+		return
+	}
+	cm.auditLog.Close()
+	cm.auditLog = nil
+}
+
+// writeAuditLog appends an entry for the operation to the audit log if
+// one is enabled. Failures are logged but otherwise ignored so that a bad
+// log file doesn't stop the operation itself.
+func (cm *Manager) writeAuditLog(op, name, newName, path string) {
+	if cm.auditLog == nil {
+		return
+	}
+
+	entry := AuditLogEntry{
+		Time:     time.Now(),
+		Op:       op,
+		Name:     name,
+		NewName:  newName,
+		Path:     path,
+		User:     cm.auditUser,
+		Hostname: cm.auditHostname,
+	}
+
+	entryJSON, err := json.Marshal(entry)
+	if err != nil {
+		groggy.Logsf("ERROR", "Failed to serialize the audit log entry for %s.\n%v\n", name, err)
+		return
+	}
+
+	_, err = cm.auditLog.Write(append(entryJSON, '\n'))
+	if err != nil {
+		groggy.Logsf("ERROR", "Failed to write the audit log entry for %s.\n%v\n", name, err)
+	}
+}
+
+// ReplayAuditLog reads the audit log at logPath and applies its operations to
+// the manager in order. Added components are loaded from their logged path if
+// they are not already in storage; ones added without a logged path can't be
+// recreated and are skipped. Saves are not replayed since the files they wrote
+// already exist and replaying must not overwrite them. Operations that have
+// already been applied are skipped so replaying the same log more than once is
+// safe. Replayed operations are not written to the manager's own audit log.
+func (cm *Manager) ReplayAuditLog(logPath string) error {
+	f, err := os.Open(logPath)
+	if err != nil {
+		return fmt.Errorf("Failed to open the audit log %s.\n%v\n", logPath, err)
+	}
+	defer f.Close()
+
+	// don't log the operations being replayed
+	auditLog := cm.auditLog
+	cm.auditLog = nil
+	defer func() { cm.auditLog = auditLog }()
+
+	scanner := bufio.NewScanner(f)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry AuditLogEntry
+		err = json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			return fmt.Errorf("Failed to decode line %d of the audit log %s.\n%v\n", lineNumber, logPath, err)
+		}
+
+		err = cm.replayAuditLogEntry(entry)
+		if err != nil {
+			return fmt.Errorf("Failed to replay line %d of the audit log %s.\n%v\n", lineNumber, logPath, err)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("Failed to read the audit log %s.\n%v\n", logPath, err)
+	}
+
+	return nil
+}
+
+// replayAuditLogEntry applies a single audit log entry to the manager.
+func (cm *Manager) replayAuditLogEntry(entry AuditLogEntry) error {
+	switch entry.Op {
+	case AuditOpAdd:
+		if _, okay := cm.storage[entry.Name]; okay {
+			return nil
+		}
+		if entry.Path == "" {
+			groggy.Logsf("ERROR", "Skipping the added component %s because no file was logged for it.\n", entry.Name)
+			return nil
+		}
+		// the file may already be loaded under the name it was later renamed to
+		if info, okay := cm.loadedFiles[entry.Path]; okay && info.Type == AssetTypeComponent {
+			return nil
+		}
+		_, err := cm.LoadComponentFromFile(entry.Path, entry.Name)
+		return err
+	case AuditOpRemove:
+		cm.RemoveComponent(entry.Name)
+		return nil
+	case AuditOpSave:
+		// the save already happened so there's nothing to write
+		return nil
+	case AuditOpRename:
+		_, oldExists := cm.storage[entry.Name]
+		_, newExists := cm.storage[entry.NewName]
+		if !oldExists && newExists {
+			return nil
+		}
+		return cm.RenameComponent(entry.Name, entry.NewName)
+	}
+
+	return fmt.Errorf("Unknown audit log operation \"%s\".", entry.Op)
+}
@@ -0,0 +1,153 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplayAuditLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fizzle_auditlog")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestComponentFile(t, dir, "a.json", "a")
+	writeTestComponentFile(t, dir, "b.json", "b")
+	savedPath := filepath.Join(dir, "saved.json")
+
+	entries := []AuditLogEntry{
+		{Op: AuditOpAdd, Name: "a", Path: filepath.Join(dir, "a.json")},
+		{Op: AuditOpAdd, Name: "b", Path: filepath.Join(dir, "b.json")},
+		{Op: AuditOpAdd, Name: "created", Path: ""},
+		{Op: AuditOpRename, Name: "b", NewName: "c"},
+		{Op: AuditOpSave, Name: "c", Path: savedPath},
+		{Op: AuditOpRemove, Name: "a"},
+	}
+	logPath := filepath.Join(dir, "audit.log")
+	var logBytes []byte
+	for _, entry := range entries {
+		entryJSON, _ := json.Marshal(entry)
+		logBytes = append(logBytes, entryJSON...)
+		logBytes = append(logBytes, '\n')
+	}
+	err = ioutil.WriteFile(logPath, logBytes, 0644)
+	if err != nil {
+		t.Fatalf("Failed to write the audit log: %v", err)
+	}
+
+	cm := NewManager(nil, nil)
+	ownLogPath := filepath.Join(dir, "own.log")
+	err = cm.EnableAuditLog(ownLogPath)
+	if err != nil {
+		t.Fatalf("Failed to enable the audit log: %v", err)
+	}
+	defer cm.DisableAuditLog()
+
+	// replaying a second time should find everything already applied
+	for i := 0; i < 2; i++ {
+		err = cm.ReplayAuditLog(logPath)
+		if err != nil {
+			t.Fatalf("Replay %d failed: %v", i, err)
+		}
+
+		if _, okay := cm.GetComponent("c"); !okay {
+			t.Errorf("The renamed component is not in storage after replay %d.", i)
+		}
+		for _, name := range []string{"a", "b", "created"} {
+			if _, okay := cm.GetComponent(name); okay {
+				t.Errorf("Component %s should not be in storage after replay %d.", name, i)
+			}
+		}
+	}
+
+	if fileExists(savedPath) {
+		t.Errorf("Replaying the save wrote the component to disk.")
+	}
+	ownLog, _ := ioutil.ReadFile(ownLogPath)
+	if len(ownLog) != 0 {
+		t.Errorf("The replayed operations were written to the manager's audit log:\n%s", ownLog)
+	}
+}
+
+func TestReplayAuditLogErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fizzle_auditlog")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cm := NewManager(nil, nil)
+	if err := cm.ReplayAuditLog(filepath.Join(dir, "missing.log")); err == nil {
+		t.Errorf("Replaying a missing log should fail.")
+	}
+
+	for _, line := range []string{"not json\n", `{"op": "explode", "name": "a"}` + "\n"} {
+		logPath := filepath.Join(dir, "bad.log")
+		ioutil.WriteFile(logPath, []byte(line), 0644)
+		if err := cm.ReplayAuditLog(logPath); err == nil {
+			t.Errorf("Replaying the line %q should fail.", line)
+		}
+	}
+}
+
+func TestAuditLogEntries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fizzle_auditlog")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cm := NewManager(nil, nil)
+	logPath := filepath.Join(dir, "audit.log")
+	err = cm.EnableAuditLog(logPath)
+	if err != nil {
+		t.Fatalf("Failed to enable the audit log: %v", err)
+	}
+
+	cm.AddComponent("a", new(Component))
+	cm.AddComponent("nil", nil)
+	cm.RenameComponent("a", "b")
+	cm.DisableAuditLog()
+
+	if _, okay := cm.GetComponent("nil"); okay {
+		t.Errorf("AddComponent stored a nil component.")
+	}
+
+	logBytes, err := ioutil.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read the audit log: %v", err)
+	}
+	var entries []AuditLogEntry
+	decoder := json.NewDecoder(bytes.NewReader(logBytes))
+	for decoder.More() {
+		var entry AuditLogEntry
+		if err := decoder.Decode(&entry); err != nil {
+			t.Fatalf("Failed to decode the audit log: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	expected := []AuditLogEntry{
+		{Op: AuditOpAdd, Name: "a"},
+		{Op: AuditOpRename, Name: "a", NewName: "b"},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("The audit log has %d entries; expected %d:\n%s", len(entries), len(expected), logBytes)
+	}
+	for i, entry := range entries {
+		if entry.Op != expected[i].Op || entry.Name != expected[i].Name || entry.NewName != expected[i].NewName {
+			t.Errorf("Audit log entry %d is %+v; expected %+v", i, entry, expected[i])
+		}
+		if entry.User != cm.auditUser || entry.Hostname != cm.auditHostname {
+			t.Errorf("Audit log entry %d has user %q on %q; expected %q on %q", i, entry.User, entry.Hostname, cm.auditUser, cm.auditHostname)
+		}
+	}
+}
@@ -44,6 +44,20 @@ type Mesh struct {
 	// the axis specified by RotationAxis.
 	RotationDegrees float32
 
+	// UVUnwrap is the method used to generate texture coordinates for the
+	// mesh if the mesh data doesn't have any. Defaults to UVUnwrapBox.
+	UVUnwrap UVUnwrapMethod `json:"uv_unwrap,omitempty"`
+
+	// AutoCenter moves the mesh vertices so that their centroid is at the
+	// origin when the mesh data is loaded. This keeps the Offset, Scale and
+	// rotation controls relative to the middle of off-center meshes.
+	AutoCenter bool `json:"auto_center,omitempty"`
+
+	// CenterOffset is the centroid that was subtracted from the vertices when
+	// AutoCenter is set. It gets added to the renderable location so that the
+	// mesh is drawn where it was modeled.
+	CenterOffset mgl.Vec3 `json:"-"`
+
 	// Parent is the owning Component object, if any.
 	Parent *Component `json:"-"`
 
@@ -176,10 +190,18 @@ type Component struct {
 	// Properties is a map for client code's custom properties for the component.
 	Properties map[string]string
 
+	// AnimationEvents are the events to trigger at specific frames while
+	// animations for the component are playing.
+	AnimationEvents []AnimationEvent `json:"anim_events"`
+
 	// componentDirPath is the directory path for the component file if it was loaded
 	// from JSON.
 	componentDirPath string
 
+	// componentFilePath is the file path for the component file if it was
+	// loaded from one.
+	componentFilePath string
+
 	// cachedRenderable is the cached renerable object for the component that can
 	// be used as a prototype.
 	cachedRenderable *fizzle.Renderable
@@ -192,10 +214,19 @@ func (c *Component) Destroy() {
 	}
 }
 
-// Clone makes a new component and then copies the members over
-// to the new object. This means that Meshes, Collisions, ChildReferences, etc...
-// are shared between the clones.
-func (c *Component) Clone() *Component {
+// Clone makes a new component and then copies the members over to the new
+// object.
+//
+// If deep is false, Meshes, Collisions, ChildReferences, etc... are shared
+// between the clones, as is the cached Renderable, which is useful for read-only
+// instances.
+//
+// If deep is true, the meshes, their mesh data, the colliders, the child
+// references, the properties and the animation events are all copied so the
+// clone can be edited without changing the original. The clone has no cached
+// Renderable, so one gets created with new buffers by GetRenderable. Bones and
+// animations in the mesh data are still shared.
+func (c *Component) Clone(deep bool) *Component {
 	clone := new(Component)
 
 	// copy over all of the fields
@@ -205,12 +236,69 @@ func (c *Component) Clone() *Component {
 	clone.ChildReferences = c.ChildReferences
 	clone.Collisions = c.Collisions
 	clone.Properties = c.Properties
+	clone.AnimationEvents = c.AnimationEvents
 	clone.componentDirPath = c.componentDirPath
+	clone.componentFilePath = c.componentFilePath
 	clone.cachedRenderable = c.cachedRenderable
 
+	if !deep {
+		return clone
+	}
+
+	clone.cachedRenderable = nil
+
+	clone.Meshes = make([]*Mesh, len(c.Meshes))
+	for i, compMesh := range c.Meshes {
+		meshClone := new(Mesh)
+		*meshClone = *compMesh
+		meshClone.Parent = clone
+		meshClone.Material.Textures = append(compMesh.Material.Textures[:0:0], compMesh.Material.Textures...)
+		if compMesh.SrcMesh != nil {
+			meshClone.SrcMesh = CopyMesh(compMesh.SrcMesh)
+		}
+		clone.Meshes[i] = meshClone
+	}
+
+	clone.ChildReferences = make([]*ChildRef, len(c.ChildReferences))
+	for i, childRef := range c.ChildReferences {
+		childClone := *childRef
+		clone.ChildReferences[i] = &childClone
+	}
+
+	clone.Collisions = make([]*CollisionRef, len(c.Collisions))
+	for i, collider := range c.Collisions {
+		colliderClone := *collider
+		colliderClone.Tags = append(collider.Tags[:0:0], collider.Tags...)
+		clone.Collisions[i] = &colliderClone
+	}
+
+	if c.Properties != nil {
+		clone.Properties = make(map[string]string, len(c.Properties))
+		for key, value := range c.Properties {
+			clone.Properties[key] = value
+		}
+	}
+
+	clone.AnimationEvents = append(c.AnimationEvents[:0:0], c.AnimationEvents...)
+	for i, event := range clone.AnimationEvents {
+		if event.Data == nil {
+			continue
+		}
+		clone.AnimationEvents[i].Data = make(map[string]interface{}, len(event.Data))
+		for key, value := range event.Data {
+			clone.AnimationEvents[i].Data[key] = value
+		}
+	}
+
 	return clone
 }
 
+// IsRenderable returns true if the component has a cached Renderable whose
+// buffers have been created and not destroyed.
+func (c *Component) IsRenderable() bool {
+	return c.cachedRenderable != nil && c.cachedRenderable.Core != nil && !c.cachedRenderable.Core.IsDestroyed
+}
+
 // SetRenderable sets the cached renderable to the one passed in as a parameter,
 // calling Destroy() on the already exisiting cached Renderable.
 func (c *Component) SetRenderable(newRenderable *fizzle.Renderable) {
@@ -282,7 +370,7 @@ func CreateRenderableForMesh(tm *fizzle.TextureManager, shaders map[string]*fizz
 	// create the new renderable
 	r := fizzle.CreateFromGombz(compMesh.SrcMesh)
 	r.Material = fizzle.NewMaterial()
-	r.Location = compMesh.Offset
+	r.Location = compMesh.Offset.Add(compMesh.CenterOffset)
 
 	// if a scale is set, copy it over to the renderable
 	if compMesh.Scale[0] != 0.0 || compMesh.Scale[1] != 0.0 || compMesh.Scale[2] != 0.0 {
@@ -0,0 +1,120 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/tbogdala/fizzle"
+)
+
+// createTestCloneComponent returns a component with every field that Clone
+// copies filled in.
+func createTestCloneComponent() *Component {
+	comp := new(Component)
+	comp.Name = "original"
+	comp.Location = mgl.Vec3{1, 2, 3}
+	comp.Properties = map[string]string{"kind": "crate"}
+	comp.componentDirPath = "assets/"
+	comp.componentFilePath = "assets/original.json"
+	comp.cachedRenderable = new(fizzle.Renderable)
+
+	compMesh := NewMesh()
+	compMesh.Name = "body"
+	compMesh.Parent = comp
+	compMesh.Material.Textures = []string{"a.png"}
+	compMesh.SrcMesh = createTestTetrahedron()
+	comp.Meshes = []*Mesh{compMesh}
+
+	comp.ChildReferences = []*ChildRef{{File: "child.json", Scale: mgl.Vec3{1, 1, 1}}}
+	comp.Collisions = []*CollisionRef{{Type: ColliderTypeSphere, Radius: 1, Tags: []string{"solid"}}}
+	comp.AnimationEvents = []AnimationEvent{{ClipName: "walk", Frame: 3, EventName: "step", Data: map[string]interface{}{"volume": 0.5}}}
+	return comp
+}
+
+func TestComponentCloneShallow(t *testing.T) {
+	original := createTestCloneComponent()
+	clone := original.Clone(false)
+
+	if clone == original {
+		t.Fatalf("Clone(false) returned the original component.")
+	}
+	if clone.Name != original.Name || clone.Location != original.Location ||
+		clone.componentDirPath != original.componentDirPath || clone.componentFilePath != original.componentFilePath {
+		t.Errorf("Clone(false) didn't copy the fields of the component.")
+	}
+
+	// everything else is shared with the original
+	if clone.cachedRenderable != original.cachedRenderable {
+		t.Errorf("Clone(false) didn't share the cached renderable.")
+	}
+	if clone.Meshes[0] != original.Meshes[0] || clone.Meshes[0].SrcMesh != original.Meshes[0].SrcMesh {
+		t.Errorf("Clone(false) didn't share the meshes.")
+	}
+	if clone.ChildReferences[0] != original.ChildReferences[0] || clone.Collisions[0] != original.Collisions[0] {
+		t.Errorf("Clone(false) didn't share the child references and colliders.")
+	}
+	clone.Properties["kind"] = "barrel"
+	if original.Properties["kind"] != "barrel" {
+		t.Errorf("Clone(false) didn't share the properties.")
+	}
+	clone.AnimationEvents[0].Frame = 5
+	if original.AnimationEvents[0].Frame != 5 {
+		t.Errorf("Clone(false) didn't share the animation events.")
+	}
+}
+
+func TestComponentCloneDeep(t *testing.T) {
+	original := createTestCloneComponent()
+	originalVertex := original.Meshes[0].SrcMesh.Vertices[0]
+	clone := original.Clone(true)
+
+	if clone.Name != original.Name || clone.Location != original.Location ||
+		clone.componentDirPath != original.componentDirPath || clone.componentFilePath != original.componentFilePath {
+		t.Errorf("Clone(true) didn't copy the fields of the component.")
+	}
+	if clone.cachedRenderable != nil {
+		t.Errorf("Clone(true) kept the cached renderable.")
+	}
+	if len(clone.Meshes) != 1 || clone.Meshes[0] == original.Meshes[0] {
+		t.Fatalf("Clone(true) didn't copy the meshes.")
+	}
+	if clone.Meshes[0].Parent != clone {
+		t.Errorf("The cloned mesh's parent is not the clone.")
+	}
+
+	// change everything in the clone and make sure the original is untouched
+	clone.Meshes[0].Name = "changed"
+	clone.Meshes[0].Material.Textures[0] = "b.png"
+	clone.Meshes[0].SrcMesh.Vertices[0] = mgl.Vec3{9, 9, 9}
+	clone.ChildReferences[0].File = "other.json"
+	clone.Collisions[0].Radius = 5
+	clone.Collisions[0].Tags[0] = "trigger"
+	clone.Properties["kind"] = "barrel"
+	clone.AnimationEvents[0].Frame = 5
+	clone.AnimationEvents[0].Data["volume"] = 1.0
+
+	if original.Meshes[0].Name != "body" || original.Meshes[0].Parent != original {
+		t.Errorf("Changing the cloned mesh changed the original mesh.")
+	}
+	if original.Meshes[0].Material.Textures[0] != "a.png" {
+		t.Errorf("Changing the cloned material textures changed the original to %v.", original.Meshes[0].Material.Textures)
+	}
+	if original.Meshes[0].SrcMesh.Vertices[0] != originalVertex {
+		t.Errorf("Changing the cloned mesh data moved the original vertex to %v.", original.Meshes[0].SrcMesh.Vertices[0])
+	}
+	if original.ChildReferences[0].File != "child.json" {
+		t.Errorf("Changing the cloned child reference changed the original.")
+	}
+	if original.Collisions[0].Radius != 1 || original.Collisions[0].Tags[0] != "solid" {
+		t.Errorf("Changing the cloned collider changed the original.")
+	}
+	if original.Properties["kind"] != "crate" {
+		t.Errorf("Changing the cloned properties changed the original.")
+	}
+	if original.AnimationEvents[0].Frame != 3 || original.AnimationEvents[0].Data["volume"] != 0.5 {
+		t.Errorf("Changing the cloned animation events changed the original.")
+	}
+}
@@ -18,13 +18,22 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"sort"
+	"sync"
 
 	"github.com/tbogdala/fizzle"
 	"github.com/tbogdala/gombz"
 	"github.com/tbogdala/groggy"
 )
 
+const (
+	// pendingComponentsBufferSize is the number of decoded components that can
+	// be waiting for ProcessPendingComponents() before worker goroutines block.
+	pendingComponentsBufferSize = 16
+)
+
 // Manager loads and manages access to Component objects.
 // Component files are defined in JSON notation which is a serialized
 // version of Component.
@@ -44,6 +53,24 @@ type Manager struct {
 	// these shaders by name and upon Renderable construction, the
 	// correct shader will be set.
 	loadedShaders map[string]*fizzle.RenderShader
+
+	// pendingComponents receives components that have been decoded by worker
+	// goroutines and are waiting for their graphics resources to be loaded
+	// and to be placed in storage on the main goroutine.
+	pendingComponents chan *Component
+
+	// loadedFiles keeps track of the files that have been loaded indexed
+	// by file path so that stale files can be found.
+	loadedFiles map[string]AssetFileInfo
+
+	// auditLog is the file operations on the stored components are logged
+	// to or nil if the audit log is not enabled.
+	auditLog *os.File
+
+	// auditUser and auditHostname are the user name and host name written
+	// to each audit log entry, looked up once when the audit log is enabled.
+	auditUser     string
+	auditHostname string
 }
 
 // NewManager creates a new Manager object using the
@@ -53,6 +80,8 @@ func NewManager(tm *fizzle.TextureManager, shaders map[string]*fizzle.RenderShad
 	cm.storage = make(map[string]*Component)
 	cm.textureManager = tm
 	cm.loadedShaders = shaders
+	cm.pendingComponents = make(chan *Component, pendingComponentsBufferSize)
+	cm.loadedFiles = make(map[string]AssetFileInfo)
 	return cm
 }
 
@@ -63,12 +92,85 @@ func (cm *Manager) Destroy() {
 		c.Destroy()
 	}
 	cm.storage = make(map[string]*Component)
+	cm.loadedFiles = make(map[string]AssetFileInfo)
+	cm.DisableAuditLog()
 }
 
 // AddComponent adds a new component to the collection. If one existed previous using
-// the same name, then it is overwritten.
+// the same name, then it is overwritten. A nil component is not added.
 func (cm *Manager) AddComponent(name string, component *Component) {
+	if component == nil {
+		groggy.Logsf("ERROR", "AddComponent: Component %s was nil and was not added.\n", name)
+		return
+	}
 	cm.storage[name] = component
+	cm.writeAuditLog(AuditOpAdd, name, "", component.componentFilePath)
+}
+
+// RemoveComponent removes the component with the name specified from the collection
+// and returns it so that the caller can destroy it if it's no longer needed.
+// A bool is returned as the second value to indicate whether or not the component
+// was found in storage.
+func (cm *Manager) RemoveComponent(name string) (*Component, bool) {
+	component, okay := cm.storage[name]
+	if !okay {
+		return nil, false
+	}
+
+	delete(cm.storage, name)
+	cm.ForgetLoadedComponentFiles(name)
+	cm.writeAuditLog(AuditOpRemove, name, "", "")
+	return component, true
+}
+
+// RenameComponent changes the name a component is stored under. An error is
+// returned if there's no component with the old name or if the new name is
+// already in use.
+func (cm *Manager) RenameComponent(oldName string, newName string) error {
+	component, okay := cm.storage[oldName]
+	if !okay {
+		return fmt.Errorf("Failed to rename component %s because it is not in storage.", oldName)
+	}
+	if _, exists := cm.storage[newName]; exists {
+		return fmt.Errorf("Failed to rename component %s because %s is already in storage.", oldName, newName)
+	}
+
+	delete(cm.storage, oldName)
+	cm.storage[newName] = component
+	for path, info := range cm.loadedFiles {
+		if info.Type != AssetTypeTexture && info.StorageName == oldName {
+			info.StorageName = newName
+			cm.loadedFiles[path] = info
+		}
+	}
+	cm.writeAuditLog(AuditOpRename, oldName, newName, "")
+	return nil
+}
+
+// SaveComponentToFile serializes the stored component with the name specified
+// to a JSON file, or to a TOML file if the filename has the TOMLFileExtension.
+func (cm *Manager) SaveComponentToFile(name string, filename string) error {
+	if isTOMLFile(filename) {
+		return cm.SaveComponentToTOML(name, filename)
+	}
+
+	component, okay := cm.storage[name]
+	if !okay {
+		return fmt.Errorf("Failed to save component %s because it is not in storage.", name)
+	}
+
+	compJSON, err := json.MarshalIndent(component, "", "    ")
+	if err != nil {
+		return fmt.Errorf("Failed to serialize component %s to JSON.\n%v\n", name, err)
+	}
+
+	err = ioutil.WriteFile(filename, compJSON, 0744)
+	if err != nil {
+		return fmt.Errorf("Failed to write component %s to %s.\n%v\n", name, filename, err)
+	}
+
+	cm.writeAuditLog(AuditOpSave, name, "", filename)
+	return nil
 }
 
 // GetComponent returns a component from storage that matches the name specified.
@@ -79,6 +181,20 @@ func (cm *Manager) GetComponent(name string) (*Component, bool) {
 	return crComponent, okay
 }
 
+// TransformAllComponents calls the function for every component in storage,
+// in order of their storage names, so that bulk edits can be made to all of them.
+func (cm *Manager) TransformAllComponents(fn func(c *Component)) {
+	names := make([]string, 0, len(cm.storage))
+	for name := range cm.storage {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fn(cm.storage[name])
+	}
+}
+
 // GetRenderableInstance gets the renderable from the component and clones it to
 // a new instance. It then loops over all child references and calls GetRenderableInstance
 // for all of them, creating new clones for each, recursively.
@@ -111,24 +227,26 @@ func (cm *Manager) GetRenderableInstance(component *Component) *fizzle.Renderabl
 }
 
 // LoadComponentFromFile loads a component from a JSON file and stores it under
-// the name speicified. This function returns the new component and a possible
-// error value.
+// the name speicified. Files with the TOMLFileExtension are loaded with
+// LoadComponentFromTOML instead. This function returns the new component and
+// a possible error value.
 func (cm *Manager) LoadComponentFromFile(filename string, storageName string) (*Component, error) {
-	// split the directory path to the component file
-	componentDirPath, _ := filepath.Split(filename)
-
 	// check to see if it exists in storage already
 	if loadedComp, okay := cm.storage[storageName]; okay {
 		return loadedComp, nil
 	}
 
-	// make sure the component file exists
-	jsonBytes, err := ioutil.ReadFile(filename)
+	if isTOMLFile(filename) {
+		return cm.LoadComponentFromTOML(filename, storageName)
+	}
+
+	component, err := readComponentFile(filename)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to read the component file specified.\n%s\n", err)
+		return nil, err
 	}
 
-	return cm.LoadComponentFromBytes(jsonBytes, storageName, componentDirPath)
+	cm.finishLoadingComponent(component, storageName)
+	return component, nil
 }
 
 // LoadComponentFromBytes loads the component from a JSON byte slice and stores it
@@ -136,6 +254,141 @@ func (cm *Manager) LoadComponentFromFile(filename string, storageName string) (*
 // parts of the component to load. This function returns the new component and
 // a possible error value.
 func (cm *Manager) LoadComponentFromBytes(jsonBytes []byte, storageName string, componentDirPath string) (*Component, error) {
+	component, err := decodeComponent(jsonBytes, componentDirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	cm.finishLoadingComponent(component, storageName)
+	return component, nil
+}
+
+// LoadComponentsFromDirectoryConcurrent loads all of the component JSON files in
+// the directory specified, storing each one under its file name. The files are
+// read and decoded by a pool of worker goroutines while the textures get loaded
+// and the components get stored on the calling goroutine, which must be the
+// one that owns the graphics context. The number of files in the directory
+// whose components are in storage afterwards is returned along with any
+// errors encountered.
+func (cm *Manager) LoadComponentsFromDirectoryConcurrent(dir string, workers int) (int, []error) {
+	filenames, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return 0, []error{fmt.Errorf("Failed to list the component files in %s.\n%v\n", dir, err)}
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan string)
+	errs := make(chan error)
+	done := make(chan bool)
+
+	// spin up the workers to read and decode the component files
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for filename := range jobs {
+				component, err := readComponentFile(filename)
+				if err != nil {
+					errs <- err
+					continue
+				}
+				cm.pendingComponents <- component
+			}
+		}()
+	}
+
+	// feed the workers and then signal when they have all finished
+	go func() {
+		for _, filename := range filenames {
+			jobs <- filename
+		}
+		close(jobs)
+		wg.Wait()
+		close(done)
+	}()
+
+	var loadErrors []error
+	for {
+		select {
+		case component := <-cm.pendingComponents:
+			cm.storePendingComponent(component)
+		case err := <-errs:
+			loadErrors = append(loadErrors, err)
+		case <-done:
+			cm.ProcessPendingComponents()
+
+			// count the files that ended up in storage since a component may
+			// have been loaded earlier as the child of another one
+			loadedCount := 0
+			for _, filename := range filenames {
+				_, storageName := filepath.Split(filename)
+				if _, okay := cm.storage[storageName]; okay {
+					loadedCount++
+				}
+			}
+			return loadedCount, loadErrors
+		}
+	}
+}
+
+// ProcessPendingComponents loads the graphics resources for all of the components
+// that have been decoded by worker goroutines and places them into storage.
+// This must be called from the goroutine that owns the graphics context.
+// Returns the number of components that were stored.
+func (cm *Manager) ProcessPendingComponents() int {
+	storedCount := 0
+	for {
+		select {
+		case component := <-cm.pendingComponents:
+			if cm.storePendingComponent(component) {
+				storedCount++
+			}
+		default:
+			return storedCount
+		}
+	}
+}
+
+// storePendingComponent finishes loading a component decoded by a worker goroutine
+// and stores it under its file name. Returns false if a component was already
+// stored under that name.
+func (cm *Manager) storePendingComponent(component *Component) bool {
+	_, storageName := filepath.Split(component.componentFilePath)
+	if _, okay := cm.storage[storageName]; okay {
+		return false
+	}
+
+	cm.finishLoadingComponent(component, storageName)
+	return true
+}
+
+// readComponentFile reads the component JSON file and decodes it. No graphics
+// calls are made so this is safe to call from any goroutine.
+func readComponentFile(filename string) (*Component, error) {
+	// split the directory path to the component file
+	componentDirPath, _ := filepath.Split(filename)
+
+	// make sure the component file exists
+	jsonBytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the component file specified.\n%s\n", err)
+	}
+
+	component, err := decodeComponent(jsonBytes, componentDirPath)
+	if err != nil {
+		return nil, err
+	}
+	component.componentFilePath = filename
+
+	return component, nil
+}
+
+// decodeComponent decodes the component JSON and loads the mesh data for it.
+// No graphics calls are made so this is safe to call from any goroutine.
+func decodeComponent(jsonBytes []byte, componentDirPath string) (*Component, error) {
 	// attempt to decode the json
 	component := new(Component)
 	err := json.Unmarshal(jsonBytes, component)
@@ -154,40 +407,34 @@ func (cm *Manager) LoadComponentFromBytes(jsonBytes []byte, storageName string,
 		}
 	}
 
+	return component, nil
+}
+
+// finishLoadingComponent loads the textures for the component, stores it under the
+// name specified and then loads any child components that are not loaded yet.
+func (cm *Manager) finishLoadingComponent(component *Component, storageName string) {
+	componentDirPath := component.componentDirPath
+
 	// load the associated textures
 	for meshIndex, compMesh := range component.Meshes {
 		for i := range compMesh.Material.Textures {
-			_, err = cm.textureManager.LoadTexture(compMesh.Material.Textures[i], compMesh.GetFullTexturePath(i))
-			if err != nil {
-				groggy.Logsf("ERROR", "Mesh #%d failed to load texture: %s", meshIndex, compMesh.Material.Textures[i])
-			} else {
-				groggy.Logsf("DEBUG", "Mesh #%d loaded texture: %s", meshIndex, compMesh.Material.Textures[i])
-			}
+			cm.loadComponentTexture(meshIndex, "texture", compMesh.Material.Textures[i], compMesh.GetFullTexturePath(i))
 		}
 		if len(compMesh.Material.DiffuseTexture) > 0 {
-			_, err = cm.textureManager.LoadTexture(compMesh.Material.DiffuseTexture, compMesh.Parent.componentDirPath+compMesh.Material.DiffuseTexture)
-			if err != nil {
-				groggy.Logsf("ERROR", "Mesh #%d failed to load diffuse texture: %s", meshIndex, compMesh.Material.DiffuseTexture)
-			} else {
-				groggy.Logsf("DEBUG", "Mesh #%d loaded diffuse texture: %s", meshIndex, compMesh.Material.DiffuseTexture)
-			}
+			cm.loadComponentTexture(meshIndex, "diffuse texture", compMesh.Material.DiffuseTexture, compMesh.Parent.componentDirPath+compMesh.Material.DiffuseTexture)
 		}
 		if len(compMesh.Material.NormalsTexture) > 0 {
-			_, err = cm.textureManager.LoadTexture(compMesh.Material.NormalsTexture, compMesh.Parent.componentDirPath+compMesh.Material.NormalsTexture)
-			if err != nil {
-				groggy.Logsf("ERROR", "Mesh #%d failed to load normal map texture: %s", meshIndex, compMesh.Material.NormalsTexture)
-			} else {
-				groggy.Logsf("DEBUG", "Mesh #%d loaded normal map texture: %s", meshIndex, compMesh.Material.NormalsTexture)
-			}
+			cm.loadComponentTexture(meshIndex, "normal map texture", compMesh.Material.NormalsTexture, compMesh.Parent.componentDirPath+compMesh.Material.NormalsTexture)
 		}
 		if len(compMesh.Material.SpecularTexture) > 0 {
-			_, err = cm.textureManager.LoadTexture(compMesh.Material.SpecularTexture, compMesh.Parent.componentDirPath+compMesh.Material.SpecularTexture)
-			if err != nil {
-				groggy.Logsf("ERROR", "Mesh #%d failed to load specular map texture: %s", meshIndex, compMesh.Material.SpecularTexture)
-			} else {
-				groggy.Logsf("DEBUG", "Mesh #%d loaded specular map texture: %s", meshIndex, compMesh.Material.SpecularTexture)
-			}
+			cm.loadComponentTexture(meshIndex, "specular map texture", compMesh.Material.SpecularTexture, compMesh.Parent.componentDirPath+compMesh.Material.SpecularTexture)
 		}
+		if len(compMesh.BinFile) > 0 {
+			cm.RecordLoadedFile(AssetTypeMesh, storageName, compMesh.GetFullBinFilePath())
+		}
+	}
+	if component.componentFilePath != "" {
+		cm.RecordLoadedFile(AssetTypeComponent, storageName, component.componentFilePath)
 	}
 
 	// place the new component into storage before parsing children
@@ -202,14 +449,26 @@ func (cm *Manager) LoadComponentFromBytes(jsonBytes []byte, storageName string,
 			continue
 		}
 
-		_, err := cm.LoadComponentFromFile(componentDirPath+childRef.File, storageName)
+		_, err := cm.LoadComponentFromFile(componentDirPath+childRef.File, childFileName)
 		if err != nil {
 			groggy.Logsf("ERROR", "Component %s has a ChildInstance (%s) could not be loaded.\n%v", component.Name, childRef.File, err)
 		}
 	}
 
 	groggy.Logsf("DEBUG", "Component \"%s\" has been loaded", component.Name)
-	return component, nil
+}
+
+// loadComponentTexture loads the texture for the mesh at meshIndex and logs the
+// result using the description of the texture (e.g. "diffuse texture").
+func (cm *Manager) loadComponentTexture(meshIndex int, description string, name string, path string) {
+	_, err := cm.textureManager.LoadTexture(name, path)
+	if err != nil {
+		groggy.Logsf("ERROR", "Mesh #%d failed to load %s: %s", meshIndex, description, name)
+		return
+	}
+
+	cm.RecordLoadedFile(AssetTypeTexture, name, path)
+	groggy.Logsf("DEBUG", "Mesh #%d loaded %s: %s", meshIndex, description, name)
 }
 
 func loadMeshForComponent(component *Component, compMesh *Mesh) error {
@@ -227,6 +486,18 @@ func loadMeshForComponent(component *Component, compMesh *Mesh) error {
 		if err != nil {
 			return fmt.Errorf("Failed to deocde the binary file (%s) for the ComponentMesh.\n%v\n", compMesh.BinFile, err)
 		}
+
+		if compMesh.AutoCenter {
+			compMesh.CenterOffset = CenterMesh(compMesh.SrcMesh)
+		}
+
+		// generate texture coordinates for meshes that don't have them
+		if len(compMesh.SrcMesh.UVChannels) == 0 || len(compMesh.SrcMesh.UVChannels[0]) == 0 {
+			err = compMesh.AutoUnwrapUV(compMesh.UVUnwrap)
+			if err != nil {
+				return fmt.Errorf("Failed to generate texture coordinates for the binary file (%s) for the ComponentMesh.\n%v\n", compMesh.BinFile, err)
+			}
+		}
 	}
 
 	return nil
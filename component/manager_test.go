@@ -0,0 +1,140 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// writeTestComponentFile writes a component file with no meshes that references
+// the child component files specified.
+func writeTestComponentFile(t *testing.T, dir, filename, name string, children ...string) {
+	childRefs := ""
+	for i, child := range children {
+		if i > 0 {
+			childRefs += ","
+		}
+		childRefs += fmt.Sprintf(`{"File": "%s", "Scale": [1, 1, 1]}`, child)
+	}
+	compJSON := fmt.Sprintf(`{"Name": "%s", "ChildReferences": [%s]}`, name, childRefs)
+	err := ioutil.WriteFile(filepath.Join(dir, filename), []byte(compJSON), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write the test component %s: %v", filename, err)
+	}
+}
+
+func TestLoadComponentFromFileWithChildren(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fizzle_components")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the parent is stored under a name that isn't a file name so that the
+	// children can't be mistaken for it
+	writeTestComponentFile(t, dir, "parent.json", "parent", "child.json", "other.json")
+	writeTestComponentFile(t, dir, "child.json", "child", "grandchild.json")
+	writeTestComponentFile(t, dir, "other.json", "other")
+	writeTestComponentFile(t, dir, "grandchild.json", "grandchild")
+
+	cm := NewManager(nil, nil)
+	parent, err := cm.LoadComponentFromFile(filepath.Join(dir, "parent.json"), "Parent")
+	if err != nil {
+		t.Fatalf("Failed to load the parent component: %v", err)
+	}
+	if stored, _ := cm.GetComponent("Parent"); stored != parent || parent.Name != "parent" {
+		t.Errorf("The parent component was replaced in storage by %v.", stored)
+	}
+
+	expected := map[string]string{
+		"child.json":      "child",
+		"other.json":      "other",
+		"grandchild.json": "grandchild",
+	}
+	for storageName, name := range expected {
+		comp, okay := cm.GetComponent(storageName)
+		if !okay {
+			t.Errorf("Child component %s is not in storage.", storageName)
+			continue
+		}
+		if comp.Name != name {
+			t.Errorf("Child component %s is %s; expected %s", storageName, comp.Name, name)
+		}
+	}
+}
+
+func TestLoadComponentsFromDirectoryConcurrent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fizzle_components")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the parents load their children synchronously, which can happen before
+	// the children's own pending copies get stored
+	const componentCount = 24
+	for i := 0; i < componentCount; i++ {
+		filename := fmt.Sprintf("comp%02d.json", i)
+		if i%3 == 0 && i+1 < componentCount {
+			writeTestComponentFile(t, dir, filename, filename, fmt.Sprintf("comp%02d.json", i+1))
+		} else {
+			writeTestComponentFile(t, dir, filename, filename)
+		}
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "broken.json"), []byte("{ not json"), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write the broken test component: %v", err)
+	}
+
+	cm := NewManager(nil, nil)
+	loadedCount, errs := cm.LoadComponentsFromDirectoryConcurrent(dir, 4)
+	if loadedCount != componentCount {
+		t.Errorf("Loaded %d components; expected %d", loadedCount, componentCount)
+	}
+	if len(errs) != 1 {
+		t.Errorf("Got %d errors; expected 1 for the broken file", len(errs))
+	}
+
+	for i := 0; i < componentCount; i++ {
+		storageName := fmt.Sprintf("comp%02d.json", i)
+		comp, okay := cm.GetComponent(storageName)
+		if !okay {
+			t.Errorf("Component %s is not in storage.", storageName)
+			continue
+		}
+		if comp.Name != storageName {
+			t.Errorf("Component %s was stored as %s.", comp.Name, storageName)
+		}
+	}
+}
+
+func TestTransformAllComponents(t *testing.T) {
+	cm := NewManager(nil, nil)
+	for _, name := range []string{"c", "a", "b"} {
+		comp := new(Component)
+		comp.Name = name
+		cm.storage[name] = comp
+	}
+
+	var visited []string
+	cm.TransformAllComponents(func(c *Component) {
+		visited = append(visited, c.Name)
+		c.Location = c.Location.Add(mgl.Vec3{1, 0, 0})
+	})
+
+	if len(visited) != 3 || visited[0] != "a" || visited[1] != "b" || visited[2] != "c" {
+		t.Errorf("Components were visited in the order %v; expected [a b c]", visited)
+	}
+	for name, comp := range cm.storage {
+		if comp.Location[0] != 1.0 {
+			t.Errorf("Component %s was not transformed.", name)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"math"
+	"math/rand"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/tbogdala/gombz"
+)
+
+// SmoothMesh returns a copy of the mesh with iterations passes of Laplacian smoothing
+// applied to it. Each pass moves every vertex towards the average position of the
+// vertices it shares an edge with by the factor specified, so a factor of 1.0
+// moves a vertex all the way to the average and 0.0 leaves it unchanged.
+// Normals are recalculated for the smoothed mesh.
+//
+// NOTE: Vertices are only connected through the faces that index them, so
+// meshes with split vertices (e.g. hard edges or UV seams) will pull apart
+// along those seams.
+func SmoothMesh(mesh *gombz.Mesh, iterations int, factor float32) *gombz.Mesh {
+	smoothed := CopyMesh(mesh)
+	neighbors := buildVertexAdjacency(mesh)
+
+	positions := smoothed.Vertices
+	nextPositions := make([]mgl.Vec3, len(positions))
+	for pass := 0; pass < iterations; pass++ {
+		for vi, p := range positions {
+			if len(neighbors[vi]) == 0 {
+				nextPositions[vi] = p
+				continue
+			}
+
+			var average mgl.Vec3
+			for _, ni := range neighbors[vi] {
+				average = average.Add(positions[ni])
+			}
+			average = average.Mul(1.0 / float32(len(neighbors[vi])))
+			nextPositions[vi] = p.Add(average.Sub(p).Mul(factor))
+		}
+		positions, nextPositions = nextPositions, positions
+	}
+	smoothed.Vertices = positions
+
+	calculateNormals(smoothed)
+	return smoothed
+}
+
+// MeshCentroid returns the centroid (the average vertex position) of the mesh.
+// A mesh without vertices has its centroid at the origin.
+func MeshCentroid(mesh *gombz.Mesh) mgl.Vec3 {
+	var centroid mgl.Vec3
+	if len(mesh.Vertices) == 0 {
+		return centroid
+	}
+
+	for _, v := range mesh.Vertices {
+		centroid = centroid.Add(v)
+	}
+	return centroid.Mul(1.0 / float32(len(mesh.Vertices)))
+}
+
+// CenterMesh moves the vertices of the mesh so that their centroid is at the
+// origin. The centroid is returned so that callers can add it to the mesh
+// location to keep the mesh where it was.
+func CenterMesh(mesh *gombz.Mesh) mgl.Vec3 {
+	centroid := MeshCentroid(mesh)
+	for i, v := range mesh.Vertices {
+		mesh.Vertices[i] = v.Sub(centroid)
+	}
+
+	return centroid
+}
+
+// DisplaceMesh returns a copy of the mesh with each vertex moved along its normal
+// by Perlin noise sampled at the vertex's XZ position multiplied by noiseScale.
+// The noise is in the range [-1..1] and is multiplied by amplitude. The seed
+// selects the noise pattern so the same seed always gives the same result.
+// Normals are recalculated for the displaced mesh unless amplitude is 0, in which
+// case the copy is identical to the original.
+func DisplaceMesh(mesh *gombz.Mesh, noiseScale, amplitude float32, seed int64) *gombz.Mesh {
+	displaced := CopyMesh(mesh)
+	if amplitude == 0.0 {
+		return displaced
+	}
+
+	noise := newPerlinNoise(seed)
+	for i, v := range displaced.Vertices {
+		if i >= len(mesh.Normals) {
+			break
+		}
+		n := noise.At(float64(v[0]*noiseScale), float64(v[2]*noiseScale))
+		displaced.Vertices[i] = v.Add(mesh.Normals[i].Mul(float32(n) * amplitude))
+	}
+
+	calculateNormals(displaced)
+	return displaced
+}
+
+// perlinNoise generates 2D gradient noise from a shuffled permutation table.
+type perlinNoise struct {
+	perm [512]int
+}
+
+// newPerlinNoise creates the permutation table for the noise using the seed.
+func newPerlinNoise(seed int64) *perlinNoise {
+	pn := new(perlinNoise)
+	shuffled := rand.New(rand.NewSource(seed)).Perm(256)
+	for i := range pn.perm {
+		pn.perm[i] = shuffled[i%256]
+	}
+	return pn
+}
+
+// At returns the noise value at the point, roughly in the range [-1..1].
+// Integer coordinates always return 0.
+func (pn *perlinNoise) At(x, y float64) float64 {
+	xFloor := math.Floor(x)
+	yFloor := math.Floor(y)
+	xi := int(xFloor) & 255
+	yi := int(yFloor) & 255
+	xf := x - xFloor
+	yf := y - yFloor
+
+	u := perlinFade(xf)
+	v := perlinFade(yf)
+
+	aa := pn.perm[pn.perm[xi]+yi]
+	ab := pn.perm[pn.perm[xi]+yi+1]
+	ba := pn.perm[pn.perm[xi+1]+yi]
+	bb := pn.perm[pn.perm[xi+1]+yi+1]
+
+	x1 := perlinLerp(u, perlinGrad(aa, xf, yf), perlinGrad(ba, xf-1.0, yf))
+	x2 := perlinLerp(u, perlinGrad(ab, xf, yf-1.0), perlinGrad(bb, xf-1.0, yf-1.0))
+	return perlinLerp(v, x1, x2)
+}
+
+// perlinFade is the quintic curve 6t^5 - 15t^4 + 10t^3 used to smooth the
+// interpolation between grid points.
+func perlinFade(t float64) float64 {
+	return t * t * t * (t*(t*6.0-15.0) + 10.0)
+}
+
+// perlinLerp linearly interpolates between a and b by t.
+func perlinLerp(t, a, b float64) float64 {
+	return a + t*(b-a)
+}
+
+// perlinGrad returns the dot product of the offset with one of eight gradient
+// directions picked by the hash.
+func perlinGrad(hash int, x, y float64) float64 {
+	switch hash & 7 {
+	case 0:
+		return x + y
+	case 1:
+		return -x + y
+	case 2:
+		return x - y
+	case 3:
+		return -x - y
+	case 4:
+		return x
+	case 5:
+		return -x
+	case 6:
+		return y
+	default:
+		return -y
+	}
+}
+
+// buildVertexAdjacency returns a slice indexed by vertex that contains the indexes
+// of all of the other vertices that share an edge with it in the mesh's faces.
+func buildVertexAdjacency(mesh *gombz.Mesh) [][]uint32 {
+	neighbors := make([][]uint32, len(mesh.Vertices))
+	addNeighbor := func(a, b uint32) {
+		for _, existing := range neighbors[a] {
+			if existing == b {
+				return
+			}
+		}
+		neighbors[a] = append(neighbors[a], b)
+	}
+
+	for _, f := range mesh.Faces {
+		for i := 0; i < 3; i++ {
+			a, b := f[i], f[(i+1)%3]
+			if a == b {
+				continue
+			}
+			addNeighbor(a, b)
+			addNeighbor(b, a)
+		}
+	}
+
+	return neighbors
+}
+
+// calculateNormals replaces the normals of the mesh with ones calculated from
+// its faces. Each vertex normal is the area weighted average of the normals of
+// the faces that use it.
+func calculateNormals(mesh *gombz.Mesh) {
+	normals := make([]mgl.Vec3, len(mesh.Vertices))
+	for _, f := range mesh.Faces {
+		v0 := mesh.Vertices[f[0]]
+		// the length of the cross product is twice the face area which
+		// weights the contribution of larger faces
+		faceNormal := mesh.Vertices[f[1]].Sub(v0).Cross(mesh.Vertices[f[2]].Sub(v0))
+		normals[f[0]] = normals[f[0]].Add(faceNormal)
+		normals[f[1]] = normals[f[1]].Add(faceNormal)
+		normals[f[2]] = normals[f[2]].Add(faceNormal)
+	}
+
+	for i, n := range normals {
+		if n.Len() > 0.0 {
+			normals[i] = n.Normalize()
+		}
+	}
+	mesh.Normals = normals
+}
+
+// CopyMesh makes a new mesh with copies of the vertex data and faces of the mesh
+// passed in. Bones and animations are shared between the two meshes.
+func CopyMesh(mesh *gombz.Mesh) *gombz.Mesh {
+	c := new(gombz.Mesh)
+	*c = *mesh
+
+	c.Vertices = append(mesh.Vertices[:0:0], mesh.Vertices...)
+	c.Normals = append(mesh.Normals[:0:0], mesh.Normals...)
+	c.Tangents = append(mesh.Tangents[:0:0], mesh.Tangents...)
+	c.Faces = append(mesh.Faces[:0:0], mesh.Faces...)
+	c.VertexWeightIds = append(mesh.VertexWeightIds[:0:0], mesh.VertexWeightIds...)
+	c.VertexWeights = append(mesh.VertexWeights[:0:0], mesh.VertexWeights...)
+
+	c.UVChannels = make([][]mgl.Vec2, len(mesh.UVChannels))
+	for i, uvs := range mesh.UVChannels {
+		c.UVChannels[i] = append(uvs[:0:0], uvs...)
+	}
+
+	return c
+}
@@ -0,0 +1,211 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"unsafe"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/tbogdala/fizzle"
+	graphics "github.com/tbogdala/fizzle/graphicsprovider"
+	"github.com/tbogdala/gombz"
+)
+
+// createTestTetrahedron returns a mesh where every vertex shares an edge with
+// all of the others.
+func createTestTetrahedron() *gombz.Mesh {
+	mesh := new(gombz.Mesh)
+	mesh.Vertices = []mgl.Vec3{{1, 1, 1}, {1, -1, -1}, {-1, 1, -1}, {-1, -1, 1}}
+	mesh.Faces = []gombz.Face{{0, 1, 2}, {0, 3, 1}, {0, 2, 3}, {1, 3, 2}}
+	mesh.UVChannels = [][]mgl.Vec2{{{0, 0}, {1, 0}, {0, 1}, {1, 1}}}
+	calculateNormals(mesh)
+	return mesh
+}
+
+func TestSmoothMesh(t *testing.T) {
+	mesh := createTestTetrahedron()
+	original := CopyMesh(mesh)
+
+	// the average of the other three vertices of the tetrahedron is -v/3
+	// so each pass moves a vertex a factor of the way towards that
+	tests := []struct {
+		iterations int
+		factor     float32
+		scale      float32
+	}{
+		{0, 1.0, 1.0},
+		{3, 0.0, 1.0},
+		{1, 1.0, -1.0 / 3.0},
+		{1, 0.5, 1.0 / 3.0},
+		{2, 0.5, 1.0 / 9.0},
+	}
+
+	for _, test := range tests {
+		smoothed := SmoothMesh(mesh, test.iterations, test.factor)
+		for i, v := range smoothed.Vertices {
+			expected := original.Vertices[i].Mul(test.scale)
+			if !v.ApproxEqualThreshold(expected, 1e-5) {
+				t.Errorf("SmoothMesh(%d, %f) moved vertex %d to %v; expected %v",
+					test.iterations, test.factor, i, v, expected)
+			}
+		}
+		if len(smoothed.Normals) != len(smoothed.Vertices) {
+			t.Errorf("SmoothMesh(%d, %f) has %d normals for %d vertices",
+				test.iterations, test.factor, len(smoothed.Normals), len(smoothed.Vertices))
+		}
+	}
+
+	// the mesh passed in must not be changed
+	for i, v := range mesh.Vertices {
+		if v != original.Vertices[i] {
+			t.Errorf("SmoothMesh changed vertex %d of the source mesh to %v", i, v)
+		}
+	}
+}
+
+func TestDisplaceMeshZeroAmplitude(t *testing.T) {
+	mesh := createTestTetrahedron()
+	displaced := DisplaceMesh(mesh, 0.37, 0.0, 42)
+
+	if len(displaced.Vertices) != len(mesh.Vertices) || len(displaced.Normals) != len(mesh.Normals) {
+		t.Fatalf("DisplaceMesh with amplitude 0 returned %d vertices and %d normals; expected %d and %d",
+			len(displaced.Vertices), len(displaced.Normals), len(mesh.Vertices), len(mesh.Normals))
+	}
+	for i := range mesh.Vertices {
+		if displaced.Vertices[i] != mesh.Vertices[i] {
+			t.Errorf("DisplaceMesh with amplitude 0 moved vertex %d to %v; expected %v", i, displaced.Vertices[i], mesh.Vertices[i])
+		}
+		if displaced.Normals[i] != mesh.Normals[i] {
+			t.Errorf("DisplaceMesh with amplitude 0 changed normal %d to %v; expected %v", i, displaced.Normals[i], mesh.Normals[i])
+		}
+	}
+
+	// the result is a copy so changing it leaves the mesh passed in alone
+	displaced.Vertices[0] = mgl.Vec3{9, 9, 9}
+	if mesh.Vertices[0] == displaced.Vertices[0] {
+		t.Errorf("DisplaceMesh with amplitude 0 returned vertices shared with the source mesh")
+	}
+}
+
+func TestDisplaceMeshSeed(t *testing.T) {
+	mesh := createTestTetrahedron()
+	first := DisplaceMesh(mesh, 0.37, 0.5, 42)
+	second := DisplaceMesh(mesh, 0.37, 0.5, 42)
+	for i := range first.Vertices {
+		if first.Vertices[i] != second.Vertices[i] {
+			t.Errorf("DisplaceMesh with the same seed moved vertex %d to %v and %v", i, first.Vertices[i], second.Vertices[i])
+		}
+	}
+}
+
+func TestCenterMesh(t *testing.T) {
+	mesh := new(gombz.Mesh)
+	mesh.Vertices = []mgl.Vec3{{0, 0, 0}, {2, 0, 0}, {2, 4, 0}, {0, 4, 6}}
+
+	centroid := CenterMesh(mesh)
+	expected := mgl.Vec3{1, 2, 1.5}
+	if !centroid.ApproxEqualThreshold(expected, 1e-5) {
+		t.Errorf("CenterMesh returned centroid %v; expected %v", centroid, expected)
+	}
+	if newCentroid := MeshCentroid(mesh); !newCentroid.ApproxEqualThreshold(mgl.Vec3{}, 1e-5) {
+		t.Errorf("The centered mesh has its centroid at %v; expected the origin", newCentroid)
+	}
+	if !mesh.Vertices[0].ApproxEqualThreshold(mgl.Vec3{-1, -2, -1.5}, 1e-5) {
+		t.Errorf("CenterMesh moved vertex 0 to %v; expected %v", mesh.Vertices[0], mgl.Vec3{-1, -2, -1.5})
+	}
+
+	// meshes without vertices are left at the origin
+	if centroid := CenterMesh(new(gombz.Mesh)); centroid != (mgl.Vec3{}) {
+		t.Errorf("CenterMesh of an empty mesh returned %v; expected the origin", centroid)
+	}
+}
+
+// mockBufferGraphics hands out buffer and vertex array objects so that renderables can be
+// created without OpenGL. Calling any GraphicsProvider method it doesn't
+// implement panics.
+type mockBufferGraphics struct {
+	graphics.GraphicsProvider
+
+	nextBuffer graphics.Buffer
+}
+
+func (g *mockBufferGraphics) GenBuffer() graphics.Buffer {
+	g.nextBuffer++
+	return g.nextBuffer
+}
+
+func (g *mockBufferGraphics) GenVertexArray() uint32                             { return 1 }
+func (g *mockBufferGraphics) BindBuffer(target graphics.Enum, b graphics.Buffer) {}
+func (g *mockBufferGraphics) BufferData(target graphics.Enum, size int, data unsafe.Pointer, usage graphics.Enum) {
+}
+func (g *mockBufferGraphics) Ptr(data interface{}) unsafe.Pointer { return nil }
+
+func TestLoadMeshForComponentAutoCenter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fizzle_meshops")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	mesh := createTestTetrahedron()
+	for i := range mesh.Vertices {
+		mesh.Vertices[i] = mesh.Vertices[i].Add(mgl.Vec3{4, 2, 0})
+	}
+	mesh.VertexCount = uint32(len(mesh.Vertices))
+	mesh.FaceCount = uint32(len(mesh.Faces))
+	meshBytes, err := mesh.Encode()
+	if err != nil {
+		t.Fatalf("Failed to encode the test mesh: %v", err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "mesh.gombz"), meshBytes, 0644)
+	if err != nil {
+		t.Fatalf("Failed to write the test mesh: %v", err)
+	}
+
+	defer fizzle.SetGraphics(fizzle.GetGraphics())
+	fizzle.SetGraphics(new(mockBufferGraphics))
+
+	tests := []struct {
+		autoCenter   bool
+		centerOffset mgl.Vec3
+		vertex       mgl.Vec3
+	}{
+		{false, mgl.Vec3{}, mgl.Vec3{5, 3, 1}},
+		{true, mgl.Vec3{4, 2, 0}, mgl.Vec3{1, 1, 1}},
+	}
+
+	for _, test := range tests {
+		comp := new(Component)
+		comp.componentDirPath = dir + string(os.PathSeparator)
+		compMesh := NewMesh()
+		compMesh.BinFile = "mesh.gombz"
+		compMesh.AutoCenter = test.autoCenter
+		err = loadMeshForComponent(comp, compMesh)
+		if err != nil {
+			t.Fatalf("loadMeshForComponent returned an error: %v", err)
+		}
+
+		if !compMesh.CenterOffset.ApproxEqualThreshold(test.centerOffset, 1e-5) {
+			t.Errorf("loadMeshForComponent with AutoCenter %v set CenterOffset to %v; expected %v",
+				test.autoCenter, compMesh.CenterOffset, test.centerOffset)
+		}
+		if !compMesh.SrcMesh.Vertices[0].ApproxEqualThreshold(test.vertex, 1e-5) {
+			t.Errorf("loadMeshForComponent with AutoCenter %v loaded vertex 0 as %v; expected %v",
+				test.autoCenter, compMesh.SrcMesh.Vertices[0], test.vertex)
+		}
+
+		// the renderable gets placed at the offset plus the center offset so
+		// the centered mesh is still drawn where it was modeled
+		compMesh.Offset = mgl.Vec3{0, 0, 10}
+		r := CreateRenderableForMesh(nil, nil, compMesh)
+		drawn := r.Location.Add(compMesh.SrcMesh.Vertices[0])
+		if !drawn.ApproxEqualThreshold(mgl.Vec3{5, 3, 11}, 1e-5) {
+			t.Errorf("The renderable with AutoCenter %v draws vertex 0 at %v; expected %v", test.autoCenter, drawn, mgl.Vec3{5, 3, 11})
+		}
+	}
+}
@@ -0,0 +1,374 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"fmt"
+	"math"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/tbogdala/gombz"
+)
+
+// PrimitiveShape is the type of the built-in shapes that CreatePrimitive can generate.
+type PrimitiveShape int
+
+const (
+	// PrimCube is a cube centered on the origin.
+	PrimCube PrimitiveShape = iota
+
+	// PrimSphere is a UV sphere centered on the origin.
+	PrimSphere
+
+	// PrimCylinder is a capped cylinder centered on the origin along the Y axis.
+	PrimCylinder
+
+	// PrimPlane is a plane on the XZ axis facing +Y.
+	PrimPlane
+
+	// PrimCapsule is a cylinder with hemispheres for caps along the Y axis.
+	PrimCapsule
+
+	// PrimitiveShapeCount is the number of primitive shapes supported.
+	PrimitiveShapeCount
+)
+
+var (
+	primitiveShapeNames = [PrimitiveShapeCount]string{"Cube", "Sphere", "Cylinder", "Plane", "Capsule"}
+)
+
+// String returns the name of the primitive shape.
+func (shape PrimitiveShape) String() string {
+	if shape < 0 || shape >= PrimitiveShapeCount {
+		return fmt.Sprintf("PrimitiveShape(%d)", int(shape))
+	}
+	return primitiveShapeNames[shape]
+}
+
+// PrimitiveParams controls the dimensions and detail of the geometry
+// created by CreatePrimitive. Fields that don't apply to a shape are ignored.
+type PrimitiveParams struct {
+	// Size is the length of the edges for cubes and planes.
+	Size float32
+
+	// Radius is the radius of spheres, cylinders and capsules.
+	Radius float32
+
+	// Height is the height of cylinders and of the cylindrical
+	// section of capsules.
+	Height float32
+
+	// Segments is the number of subdivisions around the Y axis for
+	// spheres, cylinders and capsules.
+	Segments int
+
+	// Rings is the number of subdivisions from pole to pole for
+	// spheres and capsules.
+	Rings int
+}
+
+// DefaultPrimitiveParams returns a PrimitiveParams that creates unit sized shapes.
+func DefaultPrimitiveParams() PrimitiveParams {
+	return PrimitiveParams{
+		Size:     1.0,
+		Radius:   0.5,
+		Height:   1.0,
+		Segments: 24,
+		Rings:    12,
+	}
+}
+
+// CreatePrimitive procedurally generates the geometry for the shape specified and
+// wraps it in a new Component with one Mesh named after the shape. An error
+// is returned if the shape is unknown or the parameters are invalid for the shape.
+func CreatePrimitive(shape PrimitiveShape, params PrimitiveParams) (*Component, error) {
+	var srcMesh *gombz.Mesh
+	var err error
+	switch shape {
+	case PrimCube:
+		srcMesh, err = createCubeMesh(params.Size)
+	case PrimSphere:
+		srcMesh, err = createSphereMesh(params.Radius, params.Segments, params.Rings)
+	case PrimCylinder:
+		srcMesh, err = createCylinderMesh(params.Radius, params.Height, params.Segments)
+	case PrimPlane:
+		srcMesh, err = createPlaneMesh(params.Size)
+	case PrimCapsule:
+		srcMesh, err = createCapsuleMesh(params.Radius, params.Height, params.Segments, params.Rings)
+	default:
+		return nil, fmt.Errorf("Unknown primitive shape (%d) specified.", int(shape))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	comp := new(Component)
+	comp.Name = shape.String()
+
+	compMesh := NewMesh()
+	compMesh.Name = shape.String()
+	compMesh.SrcMesh = srcMesh
+	compMesh.Parent = comp
+	comp.Meshes = []*Mesh{compMesh}
+
+	return comp, nil
+}
+
+// CreateTorus procedurally generates a torus lying on the XZ plane centered on the
+// origin. radius is the distance from the center to the middle of the tube and
+// tubeRadius is the radius of the tube itself. segments is the number of
+// subdivisions around the ring and tubeSegments is the number of
+// subdivisions around the tube.
+func CreateTorus(radius, tubeRadius float32, segments, tubeSegments int) (*gombz.Mesh, error) {
+	if radius <= 0.0 || tubeRadius <= 0.0 {
+		return nil, fmt.Errorf("A torus needs a positive radius and tube radius.")
+	}
+	if segments < 3 || tubeSegments < 3 {
+		return nil, fmt.Errorf("A torus needs at least 3 segments and 3 tube segments.")
+	}
+
+	var verts, normals []mgl.Vec3
+	var uvs []mgl.Vec2
+	var faces [][3]uint32
+
+	for s := 0; s <= segments; s++ {
+		theta := 2.0 * math.Pi * float64(s) / float64(segments)
+		ringDir := mgl.Vec3{float32(math.Cos(theta)), 0.0, float32(math.Sin(theta))}
+		ringCenter := ringDir.Mul(radius)
+
+		for t := 0; t <= tubeSegments; t++ {
+			phi := 2.0 * math.Pi * float64(t) / float64(tubeSegments)
+			n := ringDir.Mul(float32(math.Cos(phi))).Add(mgl.Vec3{0.0, float32(math.Sin(phi)), 0.0})
+			verts = append(verts, ringCenter.Add(n.Mul(tubeRadius)))
+			normals = append(normals, n)
+			uvs = append(uvs, mgl.Vec2{float32(s) / float32(segments), float32(t) / float32(tubeSegments)})
+		}
+	}
+
+	rowSize := uint32(tubeSegments + 1)
+	for s := uint32(0); s < uint32(segments); s++ {
+		for t := uint32(0); t < uint32(tubeSegments); t++ {
+			a := s*rowSize + t
+			b := a + rowSize
+			faces = append(faces, [3]uint32{a, a + 1, b})
+			faces = append(faces, [3]uint32{a + 1, b + 1, b})
+		}
+	}
+
+	return newPrimitiveMesh(verts, normals, uvs, faces), nil
+}
+
+// newPrimitiveMesh creates a new gombz Mesh from the generated geometry.
+func newPrimitiveMesh(verts, normals []mgl.Vec3, uvs []mgl.Vec2, faces [][3]uint32) *gombz.Mesh {
+	m := new(gombz.Mesh)
+	m.VertexCount = uint32(len(verts))
+	m.FaceCount = uint32(len(faces))
+	m.Vertices = verts
+	m.Normals = normals
+	m.UVChannels = [][]mgl.Vec2{uvs}
+	for _, f := range faces {
+		m.Faces = append(m.Faces, f)
+	}
+	return m
+}
+
+// appendQuad adds a quad of two triangles facing the normal n to the geometry slices.
+// The quad is centered on c and spans halfSize along the u and v axes; u cross v
+// should equal n so that the triangles wind counter-clockwise.
+func appendQuad(verts, normals []mgl.Vec3, uvs []mgl.Vec2, faces [][3]uint32,
+	c, n, u, v mgl.Vec3, halfSize float32) ([]mgl.Vec3, []mgl.Vec3, []mgl.Vec2, [][3]uint32) {
+	u = u.Mul(halfSize)
+	v = v.Mul(halfSize)
+	base := uint32(len(verts))
+
+	verts = append(verts, c.Sub(u).Sub(v), c.Add(u).Sub(v), c.Add(u).Add(v), c.Sub(u).Add(v))
+	normals = append(normals, n, n, n, n)
+	uvs = append(uvs, mgl.Vec2{0.0, 0.0}, mgl.Vec2{1.0, 0.0}, mgl.Vec2{1.0, 1.0}, mgl.Vec2{0.0, 1.0})
+	faces = append(faces, [3]uint32{base, base + 1, base + 2}, [3]uint32{base, base + 2, base + 3})
+
+	return verts, normals, uvs, faces
+}
+
+// createCubeMesh creates a cube with separate vertices for each side so that
+// the normals and UVs are correct per side.
+func createCubeMesh(size float32) (*gombz.Mesh, error) {
+	if size <= 0.0 {
+		return nil, fmt.Errorf("A cube needs a positive size.")
+	}
+
+	// each side is specified as the normal followed by the u and v axes
+	sides := [6][3]mgl.Vec3{
+		{{1, 0, 0}, {0, 0, -1}, {0, 1, 0}},
+		{{-1, 0, 0}, {0, 0, 1}, {0, 1, 0}},
+		{{0, 1, 0}, {1, 0, 0}, {0, 0, -1}},
+		{{0, -1, 0}, {1, 0, 0}, {0, 0, 1}},
+		{{0, 0, 1}, {1, 0, 0}, {0, 1, 0}},
+		{{0, 0, -1}, {-1, 0, 0}, {0, 1, 0}},
+	}
+
+	var verts, normals []mgl.Vec3
+	var uvs []mgl.Vec2
+	var faces [][3]uint32
+	halfSize := size * 0.5
+	for _, side := range sides {
+		verts, normals, uvs, faces = appendQuad(verts, normals, uvs, faces,
+			side[0].Mul(halfSize), side[0], side[1], side[2], halfSize)
+	}
+
+	return newPrimitiveMesh(verts, normals, uvs, faces), nil
+}
+
+// createPlaneMesh creates a plane on the XZ axis that faces +Y.
+func createPlaneMesh(size float32) (*gombz.Mesh, error) {
+	if size <= 0.0 {
+		return nil, fmt.Errorf("A plane needs a positive size.")
+	}
+
+	verts, normals, uvs, faces := appendQuad(nil, nil, nil, nil,
+		mgl.Vec3{0, 0, 0}, mgl.Vec3{0, 1, 0}, mgl.Vec3{1, 0, 0}, mgl.Vec3{0, 0, -1}, size*0.5)
+
+	return newPrimitiveMesh(verts, normals, uvs, faces), nil
+}
+
+// createLatheMesh builds a mesh of rings of vertices around the Y axis. For every
+// row, phis holds the angle from the +Y pole and yOffsets holds the amount to move
+// the row along Y. Consecutive rows are joined together with triangles.
+func createLatheMesh(radius float32, segments int, phis []float64, yOffsets []float32) *gombz.Mesh {
+	var verts, normals []mgl.Vec3
+	var uvs []mgl.Vec2
+	var faces [][3]uint32
+
+	rowCount := len(phis)
+	for r := 0; r < rowCount; r++ {
+		ringRadius := float32(math.Sin(phis[r]))
+		y := float32(math.Cos(phis[r]))
+		for s := 0; s <= segments; s++ {
+			theta := 2.0 * math.Pi * float64(s) / float64(segments)
+			n := mgl.Vec3{ringRadius * float32(math.Cos(theta)), y, ringRadius * float32(math.Sin(theta))}
+			verts = append(verts, n.Mul(radius).Add(mgl.Vec3{0.0, yOffsets[r], 0.0}))
+			normals = append(normals, n)
+			uvs = append(uvs, mgl.Vec2{float32(s) / float32(segments), 1.0 - float32(r)/float32(rowCount-1)})
+		}
+	}
+
+	rowSize := uint32(segments + 1)
+	for r := uint32(0); r < uint32(rowCount-1); r++ {
+		for s := uint32(0); s < uint32(segments); s++ {
+			a := r*rowSize + s
+			b := a + rowSize
+			faces = append(faces, [3]uint32{a, a + 1, b})
+			faces = append(faces, [3]uint32{a + 1, b + 1, b})
+		}
+	}
+
+	return newPrimitiveMesh(verts, normals, uvs, faces)
+}
+
+// createSphereMesh creates a UV sphere centered on the origin.
+func createSphereMesh(radius float32, segments, rings int) (*gombz.Mesh, error) {
+	if radius <= 0.0 {
+		return nil, fmt.Errorf("A sphere needs a positive radius.")
+	}
+	if segments < 3 || rings < 2 {
+		return nil, fmt.Errorf("A sphere needs at least 3 segments and 2 rings.")
+	}
+
+	phis := make([]float64, rings+1)
+	yOffsets := make([]float32, rings+1)
+	for r := 0; r <= rings; r++ {
+		phis[r] = math.Pi * float64(r) / float64(rings)
+	}
+
+	return createLatheMesh(radius, segments, phis, yOffsets), nil
+}
+
+// createCapsuleMesh creates a capsule along the Y axis centered on the origin where
+// height is the length of the cylindrical section between the two hemispheres.
+func createCapsuleMesh(radius, height float32, segments, rings int) (*gombz.Mesh, error) {
+	if radius <= 0.0 || height < 0.0 {
+		return nil, fmt.Errorf("A capsule needs a positive radius and a non-negative height.")
+	}
+	if segments < 3 || rings < 2 {
+		return nil, fmt.Errorf("A capsule needs at least 3 segments and 2 rings.")
+	}
+
+	// each hemisphere gets half of the rings and the two equators are
+	// joined to make the cylindrical section
+	hemiRings := rings / 2
+	var phis []float64
+	var yOffsets []float32
+	for r := 0; r <= hemiRings; r++ {
+		phis = append(phis, 0.5*math.Pi*float64(r)/float64(hemiRings))
+		yOffsets = append(yOffsets, height*0.5)
+	}
+	for r := 0; r <= hemiRings; r++ {
+		phis = append(phis, 0.5*math.Pi+0.5*math.Pi*float64(r)/float64(hemiRings))
+		yOffsets = append(yOffsets, height*-0.5)
+	}
+
+	return createLatheMesh(radius, segments, phis, yOffsets), nil
+}
+
+// createCylinderMesh creates a capped cylinder along the Y axis centered on the origin.
+func createCylinderMesh(radius, height float32, segments int) (*gombz.Mesh, error) {
+	if radius <= 0.0 || height <= 0.0 {
+		return nil, fmt.Errorf("A cylinder needs a positive radius and height.")
+	}
+	if segments < 3 {
+		return nil, fmt.Errorf("A cylinder needs at least 3 segments.")
+	}
+
+	var verts, normals []mgl.Vec3
+	var uvs []mgl.Vec2
+	var faces [][3]uint32
+	halfHeight := height * 0.5
+
+	// the sides are made of a bottom and top vertex for each segment
+	for s := 0; s <= segments; s++ {
+		theta := 2.0 * math.Pi * float64(s) / float64(segments)
+		n := mgl.Vec3{float32(math.Cos(theta)), 0.0, float32(math.Sin(theta))}
+		u := float32(s) / float32(segments)
+		verts = append(verts, n.Mul(radius).Add(mgl.Vec3{0.0, -halfHeight, 0.0}), n.Mul(radius).Add(mgl.Vec3{0.0, halfHeight, 0.0}))
+		normals = append(normals, n, n)
+		uvs = append(uvs, mgl.Vec2{u, 0.0}, mgl.Vec2{u, 1.0})
+	}
+	for s := uint32(0); s < uint32(segments); s++ {
+		bottom := s * 2
+		top := bottom + 1
+		faces = append(faces, [3]uint32{bottom, top, bottom + 2})
+		faces = append(faces, [3]uint32{top, top + 2, bottom + 2})
+	}
+
+	// the caps are a fan of triangles around a center vertex
+	for _, capY := range []float32{halfHeight, -halfHeight} {
+		n := mgl.Vec3{0.0, 1.0, 0.0}
+		if capY < 0.0 {
+			n = mgl.Vec3{0.0, -1.0, 0.0}
+		}
+
+		center := uint32(len(verts))
+		verts = append(verts, mgl.Vec3{0.0, capY, 0.0})
+		normals = append(normals, n)
+		uvs = append(uvs, mgl.Vec2{0.5, 0.5})
+		for s := 0; s < segments; s++ {
+			theta := 2.0 * math.Pi * float64(s) / float64(segments)
+			cos, sin := float32(math.Cos(theta)), float32(math.Sin(theta))
+			verts = append(verts, mgl.Vec3{cos * radius, capY, sin * radius})
+			normals = append(normals, n)
+			uvs = append(uvs, mgl.Vec2{0.5 + 0.5*cos, 0.5 + 0.5*sin})
+		}
+
+		for s := uint32(0); s < uint32(segments); s++ {
+			cur := center + 1 + s
+			next := center + 1 + (s+1)%uint32(segments)
+			if capY > 0.0 {
+				faces = append(faces, [3]uint32{center, next, cur})
+			} else {
+				faces = append(faces, [3]uint32{center, cur, next})
+			}
+		}
+	}
+
+	return newPrimitiveMesh(verts, normals, uvs, faces), nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"testing"
+
+	"github.com/tbogdala/gombz"
+)
+
+// checkMeshIsValid makes sure every face indexes an existing vertex and that
+// every vertex has a normal and a texture coordinate.
+func checkMeshIsValid(t *testing.T, name string, mesh *gombz.Mesh) {
+	if len(mesh.Vertices) == 0 || len(mesh.Faces) == 0 {
+		t.Errorf("%s has no vertices or faces.", name)
+	}
+	if len(mesh.Normals) != len(mesh.Vertices) {
+		t.Errorf("%s has %d normals for %d vertices.", name, len(mesh.Normals), len(mesh.Vertices))
+	}
+	if len(mesh.UVChannels) == 0 || len(mesh.UVChannels[0]) != len(mesh.Vertices) {
+		t.Errorf("%s doesn't have a texture coordinate for every vertex.", name)
+	}
+	for _, f := range mesh.Faces {
+		for _, vi := range f {
+			if int(vi) >= len(mesh.Vertices) {
+				t.Errorf("%s has a face indexing vertex %d of %d.", name, vi, len(mesh.Vertices))
+				return
+			}
+		}
+	}
+}
+
+func TestCreatePrimitiveCube(t *testing.T) {
+	comp, err := CreatePrimitive(PrimCube, DefaultPrimitiveParams())
+	if err != nil {
+		t.Fatalf("Failed to create a cube: %v", err)
+	}
+	if len(comp.Meshes) != 1 || comp.Meshes[0].Parent != comp {
+		t.Fatalf("The cube component should have one mesh parented to it.")
+	}
+
+	mesh := comp.Meshes[0].SrcMesh
+	if len(mesh.Vertices) != 24 {
+		t.Errorf("The cube has %d vertices; expected 24", len(mesh.Vertices))
+	}
+	if len(mesh.Faces)*3 != 36 {
+		t.Errorf("The cube has %d indexes; expected 36", len(mesh.Faces)*3)
+	}
+	for _, v := range mesh.Vertices {
+		for i := 0; i < 3; i++ {
+			if v[i] != 0.5 && v[i] != -0.5 {
+				t.Fatalf("The unit cube has a vertex at %v", v)
+			}
+		}
+	}
+}
+
+func TestCreatePrimitiveShapes(t *testing.T) {
+	for shape := PrimCube; shape < PrimitiveShapeCount; shape++ {
+		comp, err := CreatePrimitive(shape, DefaultPrimitiveParams())
+		if err != nil {
+			t.Errorf("Failed to create a %s: %v", shape, err)
+			continue
+		}
+		if comp.Name != shape.String() {
+			t.Errorf("The %s component is named %s.", shape, comp.Name)
+		}
+		checkMeshIsValid(t, shape.String(), comp.Meshes[0].SrcMesh)
+	}
+
+	torus, err := CreateTorus(1.0, 0.25, 16, 8)
+	if err != nil {
+		t.Fatalf("Failed to create a torus: %v", err)
+	}
+	checkMeshIsValid(t, "torus", torus)
+}
+
+func TestCreatePrimitiveErrors(t *testing.T) {
+	if _, err := CreatePrimitive(PrimitiveShapeCount, DefaultPrimitiveParams()); err == nil {
+		t.Errorf("An unknown shape should return an error.")
+	}
+
+	params := DefaultPrimitiveParams()
+	params.Segments = 2
+	if _, err := CreatePrimitive(PrimSphere, params); err == nil {
+		t.Errorf("A sphere with 2 segments should return an error.")
+	}
+
+	params = DefaultPrimitiveParams()
+	params.Size = 0.0
+	if _, err := CreatePrimitive(PrimCube, params); err == nil {
+		t.Errorf("A cube with no size should return an error.")
+	}
+
+	if _, err := CreateTorus(1.0, 0.0, 16, 8); err == nil {
+		t.Errorf("A torus with no tube radius should return an error.")
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tbogdala/gombz"
+)
+
+// ComponentReport contains statistics about the components in a Manager to
+// help track down problems when loading large projects.
+type ComponentReport struct {
+	// TotalComponents is the number of components in storage.
+	TotalComponents int
+
+	// TotalMeshes is the number of meshes in all of the components.
+	TotalMeshes int
+
+	// TotalTriangles is the sum of the face counts of all of the meshes times
+	// three, which is the number of vertex indexes drawn.
+	TotalTriangles int64
+
+	// TotalTextureCount is the number of unique texture names referenced by
+	// the mesh materials.
+	TotalTextureCount int
+
+	// EstimatedVRAMBytes is an estimate of the video memory needed for the
+	// mesh vertex data, the face indexes and the textures. Textures are
+	// counted as 32-bit RGBA with a third extra for mipmaps if enabled.
+	EstimatedVRAMBytes int64
+
+	// ComponentsWithMissingFiles is the sorted list of components that reference
+	// a mesh binary, texture or child component file that doesn't exist.
+	ComponentsWithMissingFiles []string
+
+	// CircularDependencies lists each loop of child component references as
+	// the storage names in the loop, starting with the lowest sorting name.
+	CircularDependencies [][]string
+}
+
+// GenerateReport computes the statistics for all of the components in storage.
+// Files referenced by the components are checked on disk and textures have
+// their headers read to find their size.
+func (cm *Manager) GenerateReport() ComponentReport {
+	var report ComponentReport
+
+	names := make([]string, 0, len(cm.storage))
+	for name := range cm.storage {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// textures are shared between meshes so each unique name is counted once
+	texturePaths := make(map[string]string)
+	textureMips := make(map[string]bool)
+
+	for _, name := range names {
+		component := cm.storage[name]
+		report.TotalComponents++
+
+		missingFile := false
+		for _, compMesh := range component.Meshes {
+			report.TotalMeshes++
+			if compMesh.SrcMesh != nil {
+				report.TotalTriangles += int64(len(compMesh.SrcMesh.Faces)) * 3
+				report.EstimatedVRAMBytes += estimateMeshBytes(compMesh.SrcMesh)
+			}
+
+			if len(compMesh.BinFile) > 0 && !fileExists(component.componentDirPath+compMesh.BinFile) {
+				missingFile = true
+			}
+
+			for _, texName := range getMaterialTextureNames(&compMesh.Material) {
+				texPath := component.componentDirPath + texName
+				if !fileExists(texPath) {
+					missingFile = true
+				}
+				if _, okay := texturePaths[texName]; !okay {
+					texturePaths[texName] = texPath
+				}
+				textureMips[texName] = textureMips[texName] || compMesh.Material.GenerateMipmaps
+			}
+		}
+
+		for _, childRef := range component.ChildReferences {
+			if !fileExists(component.componentDirPath + childRef.File) {
+				missingFile = true
+			}
+		}
+
+		if missingFile {
+			report.ComponentsWithMissingFiles = append(report.ComponentsWithMissingFiles, name)
+		}
+	}
+
+	report.TotalTextureCount = len(texturePaths)
+	for texName, texPath := range texturePaths {
+		report.EstimatedVRAMBytes += estimateTextureBytes(texPath, textureMips[texName])
+	}
+
+	report.CircularDependencies = cm.findCircularDependencies(names)
+	return report
+}
+
+// WriteText writes the report out in a human readable format.
+func (r ComponentReport) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "Components:           %d\n", r.TotalComponents)
+	fmt.Fprintf(w, "Meshes:               %d\n", r.TotalMeshes)
+	fmt.Fprintf(w, "Triangles:            %d\n", r.TotalTriangles)
+	fmt.Fprintf(w, "Textures:             %d\n", r.TotalTextureCount)
+	fmt.Fprintf(w, "Estimated VRAM:       %.2f MB (%d bytes)\n", float64(r.EstimatedVRAMBytes)/(1024.0*1024.0), r.EstimatedVRAMBytes)
+
+	fmt.Fprintf(w, "Missing files:        %d\n", len(r.ComponentsWithMissingFiles))
+	for _, name := range r.ComponentsWithMissingFiles {
+		fmt.Fprintf(w, "    %s\n", name)
+	}
+
+	fmt.Fprintf(w, "Circular references:  %d\n", len(r.CircularDependencies))
+	for _, cycle := range r.CircularDependencies {
+		fmt.Fprintf(w, "    %s -> %s\n", strings.Join(cycle, " -> "), cycle[0])
+	}
+}
+
+// findCircularDependencies returns every loop in the child references between
+// the components in storage. Children are identified by their file name, which
+// is the storage name finishLoadingComponent uses for them.
+func (cm *Manager) findCircularDependencies(names []string) [][]string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	cycles := [][]string{}
+	found := make(map[string]bool)
+	state := make(map[string]int)
+	stack := []string{}
+
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		stack = append(stack, name)
+
+		component := cm.storage[name]
+		for _, childRef := range component.ChildReferences {
+			_, childName := filepath.Split(childRef.File)
+			if _, okay := cm.storage[childName]; !okay {
+				continue
+			}
+
+			switch state[childName] {
+			case unvisited:
+				visit(childName)
+			case visiting:
+				// the child is on the stack so everything from it to here is a loop
+				start := len(stack) - 1
+				for stack[start] != childName {
+					start--
+				}
+				cycle := normalizeCycle(stack[start:])
+				key := strings.Join(cycle, "\x00")
+				if !found[key] {
+					found[key] = true
+					cycles = append(cycles, cycle)
+				}
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+	}
+
+	for _, name := range names {
+		if state[name] == unvisited {
+			visit(name)
+		}
+	}
+
+	return cycles
+}
+
+// normalizeCycle returns a copy of the cycle rotated so that it starts with the
+// lowest sorting name, which makes the same loop found from different starting
+// points compare equal.
+func normalizeCycle(cycle []string) []string {
+	lowest := 0
+	for i, name := range cycle {
+		if name < cycle[lowest] {
+			lowest = i
+		}
+	}
+
+	normalized := make([]string, 0, len(cycle))
+	normalized = append(normalized, cycle[lowest:]...)
+	normalized = append(normalized, cycle[:lowest]...)
+	return normalized
+}
+
+// getMaterialTextureNames returns all of the texture names used by the material.
+func getMaterialTextureNames(mat *Material) []string {
+	var texNames []string
+	for _, texName := range mat.Textures {
+		if len(texName) > 0 {
+			texNames = append(texNames, texName)
+		}
+	}
+	for _, texName := range []string{mat.DiffuseTexture, mat.NormalsTexture, mat.SpecularTexture} {
+		if len(texName) > 0 {
+			texNames = append(texNames, texName)
+		}
+	}
+	return texNames
+}
+
+// estimateMeshBytes returns the number of bytes the mesh vertex attributes and
+// face indexes take up in buffers.
+func estimateMeshBytes(mesh *gombz.Mesh) int64 {
+	const (
+		vec2Size  = 2 * 4
+		vec3Size  = 3 * 4
+		vec4Size  = 4 * 4
+		indexSize = 4
+	)
+
+	bytes := int64(len(mesh.Vertices)+len(mesh.Normals)+len(mesh.Tangents)) * vec3Size
+	for _, uvs := range mesh.UVChannels {
+		bytes += int64(len(uvs)) * vec2Size
+	}
+	bytes += int64(len(mesh.VertexWeightIds)+len(mesh.VertexWeights)) * vec4Size
+	bytes += int64(len(mesh.Faces)) * 3 * indexSize
+	return bytes
+}
+
+// estimateTextureBytes returns the number of bytes the texture takes up as RGBA
+// by reading the size from the PNG header. Textures that can't be read count
+// as zero bytes.
+func estimateTextureBytes(path string, mipmaps bool) int64 {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	config, err := png.DecodeConfig(f)
+	if err != nil {
+		return 0
+	}
+
+	bytes := int64(config.Width) * int64(config.Height) * 4
+	if mipmaps {
+		bytes += bytes / 3
+	}
+	return bytes
+}
+
+// fileExists returns true if there is a file at the path.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeCycle(t *testing.T) {
+	tests := []struct {
+		cycle    []string
+		expected []string
+	}{
+		{[]string{"a"}, []string{"a"}},
+		{[]string{"a", "b", "c"}, []string{"a", "b", "c"}},
+		{[]string{"b", "c", "a"}, []string{"a", "b", "c"}},
+		{[]string{"c", "a", "b"}, []string{"a", "b", "c"}},
+		{[]string{"d", "c", "b"}, []string{"b", "d", "c"}},
+	}
+
+	for _, test := range tests {
+		original := append([]string{}, test.cycle...)
+		normalized := normalizeCycle(test.cycle)
+		if !reflect.DeepEqual(normalized, test.expected) {
+			t.Errorf("normalizeCycle(%v) returned %v; expected %v", test.cycle, normalized, test.expected)
+		}
+		if !reflect.DeepEqual(test.cycle, original) {
+			t.Errorf("normalizeCycle changed the cycle passed in to %v", test.cycle)
+		}
+	}
+}
+
+// newReportTestManager creates a Manager with components stored under the
+// names specified and child references set from the map of parent to children.
+func newReportTestManager(dir string, children map[string][]string, names ...string) *Manager {
+	cm := NewManager(nil, nil)
+	for _, name := range names {
+		comp := new(Component)
+		comp.Name = name
+		comp.componentDirPath = dir
+		for _, child := range children[name] {
+			comp.ChildReferences = append(comp.ChildReferences, &ChildRef{File: child})
+		}
+		cm.storage[name] = comp
+	}
+	return cm
+}
+
+func TestFindCircularDependencies(t *testing.T) {
+	tests := []struct {
+		children map[string][]string
+		expected [][]string
+	}{
+		// no loops
+		{map[string][]string{"a": {"b", "c"}, "b": {"c"}}, [][]string{}},
+		// a component referencing itself
+		{map[string][]string{"a": {"a"}}, [][]string{{"a"}}},
+		// a loop found from the middle is still reported from its lowest name
+		{map[string][]string{"a": {"c"}, "c": {"d"}, "d": {"b"}, "b": {"c"}}, [][]string{{"b", "c", "d"}}},
+		// two separate loops and a child that isn't in storage
+		{map[string][]string{"a": {"b", "missing"}, "b": {"a"}, "c": {"d"}, "d": {"c"}}, [][]string{{"a", "b"}, {"c", "d"}}},
+		// child files in subdirectories are stored by file name
+		{map[string][]string{"a": {"sub/b"}, "b": {"../a"}}, [][]string{{"a", "b"}}},
+	}
+
+	for _, test := range tests {
+		cm := newReportTestManager("", test.children, "a", "b", "c", "d")
+		cycles := cm.findCircularDependencies([]string{"a", "b", "c", "d"})
+		if !reflect.DeepEqual(cycles, test.expected) {
+			t.Errorf("findCircularDependencies for %v returned %v; expected %v", test.children, cycles, test.expected)
+		}
+	}
+}
+
+func TestGenerateReportMissingFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fizzle_report")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	dir += string(filepath.Separator)
+
+	// write the files that exist: a mesh binary, an 8x8 texture and a child component
+	err = ioutil.WriteFile(dir+"present.gombz", []byte{0}, 0644)
+	if err != nil {
+		t.Fatalf("Failed to write the test mesh file: %v", err)
+	}
+	writeTestComponentFile(t, dir, "child.json", "child")
+	texFile, err := os.Create(dir + "present.png")
+	if err != nil {
+		t.Fatalf("Failed to create the test texture: %v", err)
+	}
+	err = png.Encode(texFile, image.NewNRGBA(image.Rect(0, 0, 8, 8)))
+	texFile.Close()
+	if err != nil {
+		t.Fatalf("Failed to encode the test texture: %v", err)
+	}
+
+	cm := newReportTestManager(dir, map[string][]string{
+		"goodchild":    {"child.json"},
+		"missingchild": {"nochild.json"},
+	}, "good", "goodchild", "missingbin", "missingtex", "missingchild")
+
+	addMesh := func(name, binFile, texFile string) {
+		compMesh := NewMesh()
+		compMesh.BinFile = binFile
+		compMesh.Material.DiffuseTexture = texFile
+		compMesh.Material.GenerateMipmaps = false
+		cm.storage[name].Meshes = append(cm.storage[name].Meshes, compMesh)
+	}
+	addMesh("good", "present.gombz", "present.png")
+	addMesh("missingbin", "absent.gombz", "")
+	addMesh("missingtex", "", "absent.png")
+
+	report := cm.GenerateReport()
+	expected := []string{"missingbin", "missingchild", "missingtex"}
+	if !reflect.DeepEqual(report.ComponentsWithMissingFiles, expected) {
+		t.Errorf("GenerateReport found missing files in %v; expected %v", report.ComponentsWithMissingFiles, expected)
+	}
+	if report.TotalComponents != 5 || report.TotalMeshes != 3 || report.TotalTextureCount != 2 {
+		t.Errorf("GenerateReport counted %d components, %d meshes and %d textures; expected 5, 3 and 2",
+			report.TotalComponents, report.TotalMeshes, report.TotalTextureCount)
+	}
+
+	// only the texture that exists has a size; the meshes have no data loaded
+	if report.EstimatedVRAMBytes != 8*8*4 {
+		t.Errorf("GenerateReport estimated %d bytes of VRAM; expected %d", report.EstimatedVRAMBytes, 8*8*4)
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// TOMLFileExtension is the file extension that marks a component file as TOML
+// instead of JSON.
+const TOMLFileExtension = ".toml"
+
+// isTOMLFile returns true if the filename has the TOML file extension.
+func isTOMLFile(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), TOMLFileExtension)
+}
+
+// LoadComponentFromTOML loads a component file written in TOML and stores
+// it under the name specified. The TOML uses the same keys as the JSON
+// component files. This function returns the new component and a possible
+// error value.
+func (cm *Manager) LoadComponentFromTOML(filename string, storageName string) (*Component, error) {
+	// check to see if it exists in storage already
+	if loadedComp, okay := cm.storage[storageName]; okay {
+		return loadedComp, nil
+	}
+
+	componentDirPath, _ := filepath.Split(filename)
+	tomlBytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the component file specified.\n%s\n", err)
+	}
+
+	jsonBytes, err := tomlToJSON(tomlBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	component, err := decodeComponent(jsonBytes, componentDirPath)
+	if err != nil {
+		return nil, err
+	}
+	component.componentFilePath = filename
+
+	cm.finishLoadingComponent(component, storageName)
+	return component, nil
+}
+
+// SaveComponentToTOML serializes the stored component with the name specified
+// to a TOML file.
+func (cm *Manager) SaveComponentToTOML(storageName string, filename string) error {
+	component, okay := cm.storage[storageName]
+	if !okay {
+		return fmt.Errorf("Failed to save component %s because it is not in storage.", storageName)
+	}
+
+	compTOML, err := EncodeComponentTOML(component)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(filename, compTOML, 0744)
+	if err != nil {
+		return fmt.Errorf("Failed to write component %s to %s.\n%v\n", storageName, filename, err)
+	}
+
+	cm.writeAuditLog(AuditOpSave, storageName, "", filename)
+	return nil
+}
+
+// EncodeComponentTOML serializes the component to TOML using the same keys as
+// its JSON serialization.
+func EncodeComponentTOML(component *Component) ([]byte, error) {
+	compJSON, err := json.Marshal(component)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to serialize the component to JSON.\n%v\n", err)
+	}
+
+	// decode the JSON into generic maps so that the TOML gets the JSON keys
+	decoder := json.NewDecoder(bytes.NewReader(compJSON))
+	decoder.UseNumber()
+	var doc map[string]interface{}
+	err = decoder.Decode(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode the component JSON.\n%v\n", err)
+	}
+
+	var buf bytes.Buffer
+	err = toml.NewEncoder(&buf).Encode(cleanTOMLValue(doc, reflect.TypeOf(component)))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to serialize the component to TOML.\n%v\n", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// DecodeComponentTOML decodes the component TOML into the component passed in.
+// Mesh data and textures are not loaded.
+func DecodeComponentTOML(tomlBytes []byte, component *Component) error {
+	jsonBytes, err := tomlToJSON(tomlBytes)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(jsonBytes, component)
+	if err != nil {
+		return fmt.Errorf("Failed to decode the component.\n%v\n", err)
+	}
+
+	return nil
+}
+
+// tomlToJSON converts the component TOML to JSON so that it can be decoded
+// with the same rules as the JSON component files.
+func tomlToJSON(tomlBytes []byte) ([]byte, error) {
+	var doc map[string]interface{}
+	_, err := toml.Decode(string(tomlBytes), &doc)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode the TOML in the component file specified.\n%s\n", err)
+	}
+
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to convert the component TOML to JSON.\n%v\n", err)
+	}
+
+	return jsonBytes, nil
+}
+
+// cleanTOMLValue prepares a value decoded from JSON to be encoded as TOML.
+// The type t is the Go type the value was serialized from and is used to
+// write numbers for integer fields as integers and numbers for float fields,
+// such as vectors, as floats so that they stay floats when read back. Numbers
+// without a known type are written as floats like encoding/json decodes
+// them. Null map entries and array elements are dropped since TOML has no
+// null.
+func cleanTOMLValue(v interface{}, t reflect.Type) interface{} {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch value := v.(type) {
+	case json.Number:
+		if t != nil && isIntegerKind(t.Kind()) {
+			if i, err := value.Int64(); err == nil {
+				return i
+			}
+		}
+		f, _ := value.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range value {
+			if item == nil {
+				delete(value, key)
+				continue
+			}
+			value[key] = cleanTOMLValue(item, getJSONFieldType(t, key))
+		}
+		return value
+	case []interface{}:
+		var elemType reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elemType = t.Elem()
+		}
+		cleaned := make([]interface{}, 0, len(value))
+		for _, item := range value {
+			if item == nil {
+				continue
+			}
+			cleaned = append(cleaned, cleanTOMLValue(item, elemType))
+		}
+		return cleaned
+	default:
+		return v
+	}
+}
+
+// getJSONFieldType returns the type of the value stored under the JSON key in
+// a value of type t, which is either a struct or a map. Returns nil if the type
+// isn't known.
+func getJSONFieldType(t reflect.Type, key string) reflect.Type {
+	if t == nil {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Map:
+		return t.Elem()
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "" {
+				name = field.Name
+			}
+			if name == key {
+				return field.Type
+			}
+		}
+	}
+	return nil
+}
+
+// isIntegerKind returns true if the kind is one of the signed or unsigned
+// integer kinds.
+func isIntegerKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
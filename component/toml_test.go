@@ -0,0 +1,115 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+func TestComponentTOMLRoundTrip(t *testing.T) {
+	original := new(Component)
+	original.Name = "tomltest"
+	original.Location = mgl.Vec3{1, 2, 3}
+	original.Properties = map[string]string{"kind": "crate"}
+
+	compMesh := NewMesh()
+	compMesh.Name = "body"
+	compMesh.BinFile = "body.gombz"
+	compMesh.Offset = mgl.Vec3{1, 0.5, 0}
+	compMesh.RotationAxis = mgl.Vec3{0, 1, 0}
+	compMesh.RotationDegrees = 90
+	compMesh.Material.Diffuse = mgl.Vec4{1, 1, 1, 1}
+	compMesh.Material.Shininess = 0.25
+	compMesh.Material.Textures = []string{"a.png", "b.png"}
+	compMesh.AutoCenter = true
+	original.Meshes = append(original.Meshes, compMesh)
+
+	original.ChildReferences = append(original.ChildReferences, &ChildRef{
+		File: "child.json", Location: mgl.Vec3{0, 0, 5}, Scale: mgl.Vec3{2, 2, 2}})
+	original.Collisions = append(original.Collisions, &CollisionRef{
+		Type: ColliderTypeSphere, Radius: 2, Offset: mgl.Vec3{0, 1, 0}, Tags: []string{"solid"}})
+	original.AnimationEvents = append(original.AnimationEvents, AnimationEvent{
+		ClipName: "walk", Frame: 3, EventName: "step", Data: map[string]interface{}{"volume": 0.5, "count": 2.0}})
+
+	compTOML, err := EncodeComponentTOML(original)
+	if err != nil {
+		t.Fatalf("Failed to encode the component to TOML: %v", err)
+	}
+
+	// float fields stay floats even when they hold whole numbers
+	tomlText := string(compTOML)
+	for _, expected := range []string{"Radius = 2.0", "Type = 1\n", "Frame = 3\n", "RotationDegrees = 90.0", "Location = [1.0, 2.0, 3.0]"} {
+		if !strings.Contains(tomlText, expected) {
+			t.Errorf("The component TOML doesn't contain %q:\n%s", expected, tomlText)
+		}
+	}
+
+	decoded := new(Component)
+	err = DecodeComponentTOML(compTOML, decoded)
+	if err != nil {
+		t.Fatalf("Failed to decode the component TOML: %v", err)
+	}
+
+	if decoded.Name != original.Name || decoded.Location != original.Location ||
+		!reflect.DeepEqual(decoded.Properties, original.Properties) {
+		t.Errorf("The decoded component is %+v; expected %+v", decoded, original)
+	}
+	if len(decoded.Meshes) != 1 {
+		t.Fatalf("The decoded component has %d meshes; expected 1", len(decoded.Meshes))
+	}
+	decodedMesh := decoded.Meshes[0]
+	if decodedMesh.Name != compMesh.Name || decodedMesh.BinFile != compMesh.BinFile ||
+		decodedMesh.Offset != compMesh.Offset || decodedMesh.Scale != compMesh.Scale ||
+		decodedMesh.RotationAxis != compMesh.RotationAxis || decodedMesh.RotationDegrees != compMesh.RotationDegrees ||
+		decodedMesh.AutoCenter != compMesh.AutoCenter {
+		t.Errorf("The decoded mesh is %+v; expected %+v", decodedMesh, compMesh)
+	}
+	if !reflect.DeepEqual(decodedMesh.Material, compMesh.Material) {
+		t.Errorf("The decoded material is %+v; expected %+v", decodedMesh.Material, compMesh.Material)
+	}
+	if len(decoded.ChildReferences) != 1 || !reflect.DeepEqual(*decoded.ChildReferences[0], *original.ChildReferences[0]) {
+		t.Errorf("The decoded child references are %v; expected %v", decoded.ChildReferences, original.ChildReferences)
+	}
+	if len(decoded.Collisions) != 1 || !reflect.DeepEqual(*decoded.Collisions[0], *original.Collisions[0]) {
+		t.Errorf("The decoded collisions are %v; expected %v", decoded.Collisions, original.Collisions)
+	}
+	if !reflect.DeepEqual(decoded.AnimationEvents, original.AnimationEvents) {
+		t.Errorf("The decoded animation events are %v; expected %v", decoded.AnimationEvents, original.AnimationEvents)
+	}
+}
+
+func TestComponentTOMLDropsNulls(t *testing.T) {
+	original := new(Component)
+	original.Name = "tomlnulls"
+	original.ChildReferences = []*ChildRef{nil, {File: "child.json"}, nil}
+	original.AnimationEvents = append(original.AnimationEvents, AnimationEvent{
+		ClipName: "walk", EventName: "step",
+		Data: map[string]interface{}{"volumes": []interface{}{0.5, nil, 1.0}, "sound": nil}})
+
+	compTOML, err := EncodeComponentTOML(original)
+	if err != nil {
+		t.Fatalf("Failed to encode the component with nulls to TOML: %v", err)
+	}
+
+	decoded := new(Component)
+	err = DecodeComponentTOML(compTOML, decoded)
+	if err != nil {
+		t.Fatalf("Failed to decode the component TOML: %v\n%s", err, compTOML)
+	}
+
+	if len(decoded.ChildReferences) != 1 || decoded.ChildReferences[0] == nil || decoded.ChildReferences[0].File != "child.json" {
+		t.Errorf("The decoded child references are %v; expected only child.json", decoded.ChildReferences)
+	}
+	if len(decoded.AnimationEvents) != 1 {
+		t.Fatalf("The decoded component has %d animation events; expected 1", len(decoded.AnimationEvents))
+	}
+	expectedData := map[string]interface{}{"volumes": []interface{}{0.5, 1.0}}
+	if !reflect.DeepEqual(decoded.AnimationEvents[0].Data, expectedData) {
+		t.Errorf("The decoded animation event data is %v; expected %v", decoded.AnimationEvents[0].Data, expectedData)
+	}
+}
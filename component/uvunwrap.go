@@ -0,0 +1,146 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"fmt"
+	"math"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// UVUnwrapMethod is the type of projection used to generate texture
+// coordinates for a mesh that doesn't have any.
+type UVUnwrapMethod string
+
+const (
+	// UVUnwrapBox projects each vertex onto the side of the mesh's bounding box
+	// that its normal faces most. This is the default method.
+	UVUnwrapBox UVUnwrapMethod = "box"
+
+	// UVUnwrapSphere projects the vertices onto a sphere around the center of
+	// the mesh using longitude for U and latitude for V.
+	UVUnwrapSphere UVUnwrapMethod = "sphere"
+
+	// UVUnwrapCylinder projects the vertices onto a cylinder along the Y axis
+	// using the angle around the axis for U and the height for V.
+	UVUnwrapCylinder UVUnwrapMethod = "cylinder"
+)
+
+// AutoUnwrapUV generates the first UV channel of SrcMesh from the vertex
+// positions using the projection method specified. An empty method uses
+// UVUnwrapBox. Any existing texture coordinates in the first channel are replaced.
+func (cm *Mesh) AutoUnwrapUV(method UVUnwrapMethod) error {
+	if cm.SrcMesh == nil {
+		return fmt.Errorf("No mesh data is loaded for %s to unwrap.", cm.Name)
+	}
+
+	verts := cm.SrcMesh.Vertices
+	min, max := getVertexBounds(verts)
+	center := min.Add(max).Mul(0.5)
+	size := max.Sub(min)
+
+	uvs := make([]mgl.Vec2, len(verts))
+	switch method {
+	case UVUnwrapBox, "":
+		for i, v := range verts {
+			var n mgl.Vec3
+			if i < len(cm.SrcMesh.Normals) {
+				n = cm.SrcMesh.Normals[i]
+			}
+			uvs[i] = projectBoxUV(v, n, center, min, size)
+		}
+	case UVUnwrapSphere:
+		for i, v := range verts {
+			uvs[i] = projectSphereUV(v.Sub(center))
+		}
+	case UVUnwrapCylinder:
+		for i, v := range verts {
+			uvs[i] = projectCylinderUV(v.Sub(center), min[1], size[1])
+		}
+	default:
+		return fmt.Errorf("Unknown UV unwrap method \"%s\" for %s.", method, cm.Name)
+	}
+
+	if len(cm.SrcMesh.UVChannels) == 0 {
+		cm.SrcMesh.UVChannels = [][]mgl.Vec2{uvs}
+	} else {
+		cm.SrcMesh.UVChannels[0] = uvs
+	}
+	return nil
+}
+
+// getVertexBounds returns the minimum and maximum corners of the bounding box
+// around the vertices.
+func getVertexBounds(verts []mgl.Vec3) (mgl.Vec3, mgl.Vec3) {
+	if len(verts) == 0 {
+		return mgl.Vec3{}, mgl.Vec3{}
+	}
+
+	min, max := verts[0], verts[0]
+	for _, v := range verts[1:] {
+		for i := 0; i < 3; i++ {
+			min[i] = float32(math.Min(float64(min[i]), float64(v[i])))
+			max[i] = float32(math.Max(float64(max[i]), float64(v[i])))
+		}
+	}
+	return min, max
+}
+
+// normalizeInRange returns where the value lies between start and start+length
+// in the range [0..1]. Zero length ranges always return 0.
+func normalizeInRange(value, start, length float32) float32 {
+	if length <= 0.0 {
+		return 0.0
+	}
+	return (value - start) / length
+}
+
+// projectBoxUV projects the vertex onto the side of the bounding box that the
+// normal points at most. If the normal is zero, the direction from the center
+// of the box to the vertex is used instead.
+func projectBoxUV(v, normal, center, min, size mgl.Vec3) mgl.Vec2 {
+	dir := normal
+	if dir.Len() == 0.0 {
+		dir = v.Sub(center)
+	}
+
+	ax := math.Abs(float64(dir[0]))
+	ay := math.Abs(float64(dir[1]))
+	az := math.Abs(float64(dir[2]))
+	switch {
+	case ax >= ay && ax >= az:
+		return mgl.Vec2{normalizeInRange(v[2], min[2], size[2]), normalizeInRange(v[1], min[1], size[1])}
+	case ay >= az:
+		return mgl.Vec2{normalizeInRange(v[0], min[0], size[0]), normalizeInRange(v[2], min[2], size[2])}
+	default:
+		return mgl.Vec2{normalizeInRange(v[0], min[0], size[0]), normalizeInRange(v[1], min[1], size[1])}
+	}
+}
+
+// projectSphereUV projects the position, relative to the center of the mesh,
+// onto a sphere.
+func projectSphereUV(p mgl.Vec3) mgl.Vec2 {
+	length := p.Len()
+	if length == 0.0 {
+		return mgl.Vec2{0.5, 0.5}
+	}
+
+	u := 0.5 + math.Atan2(float64(p[2]), float64(p[0]))/(2.0*math.Pi)
+	v := 0.5 + math.Asin(float64(p[1]/length))/math.Pi
+	return mgl.Vec2{float32(u), float32(v)}
+}
+
+// projectCylinderUV projects the position, relative to the center of the mesh,
+// onto a cylinder along the Y axis that starts at minY and is height tall.
+func projectCylinderUV(p mgl.Vec3, minY, height float32) mgl.Vec2 {
+	u := 0.5 + math.Atan2(float64(p[2]), float64(p[0]))/(2.0*math.Pi)
+	if p[0] == 0.0 && p[2] == 0.0 {
+		u = 0.5
+	}
+
+	// p is relative to the center so move it back to compare against minY
+	y := p[1] + minY + height*0.5
+	return mgl.Vec2{float32(u), normalizeInRange(y, minY, height)}
+}
@@ -0,0 +1,79 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package component
+
+import (
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+func TestAutoUnwrapUV(t *testing.T) {
+	methods := []UVUnwrapMethod{"", UVUnwrapBox, UVUnwrapSphere, UVUnwrapCylinder}
+	for _, method := range methods {
+		comp, err := CreatePrimitive(PrimCube, DefaultPrimitiveParams())
+		if err != nil {
+			t.Fatalf("Failed to create a cube: %v", err)
+		}
+		compMesh := comp.Meshes[0]
+		compMesh.SrcMesh.UVChannels = nil
+
+		err = compMesh.AutoUnwrapUV(method)
+		if err != nil {
+			t.Errorf("AutoUnwrapUV(%q) failed: %v", method, err)
+			continue
+		}
+
+		mesh := compMesh.SrcMesh
+		if len(mesh.UVChannels) != 1 || len(mesh.UVChannels[0]) != len(mesh.Vertices) {
+			t.Errorf("AutoUnwrapUV(%q) didn't make one texture coordinate per vertex.", method)
+			continue
+		}
+		for i, uv := range mesh.UVChannels[0] {
+			if uv[0] < 0.0 || uv[0] > 1.0 || uv[1] < 0.0 || uv[1] > 1.0 {
+				t.Errorf("AutoUnwrapUV(%q) made %v for vertex %d which is outside [0..1].", method, uv, i)
+			}
+		}
+	}
+}
+
+func TestAutoUnwrapUVBoxProjection(t *testing.T) {
+	comp, _ := CreatePrimitive(PrimCube, DefaultPrimitiveParams())
+	compMesh := comp.Meshes[0]
+	err := compMesh.AutoUnwrapUV(UVUnwrapBox)
+	if err != nil {
+		t.Fatalf("AutoUnwrapUV failed: %v", err)
+	}
+
+	// the corners of each face should be projected to the corners of the
+	// texture using the two axes the face normal doesn't point along
+	mesh := compMesh.SrcMesh
+	for i, v := range mesh.Vertices {
+		n := mesh.Normals[i]
+		var expected mgl.Vec2
+		switch {
+		case n[0] != 0.0:
+			expected = mgl.Vec2{v[2] + 0.5, v[1] + 0.5}
+		case n[1] != 0.0:
+			expected = mgl.Vec2{v[0] + 0.5, v[2] + 0.5}
+		default:
+			expected = mgl.Vec2{v[0] + 0.5, v[1] + 0.5}
+		}
+		if !mesh.UVChannels[0][i].ApproxEqualThreshold(expected, 1e-5) {
+			t.Errorf("Vertex %d at %v facing %v got %v; expected %v", i, v, n, mesh.UVChannels[0][i], expected)
+		}
+	}
+}
+
+func TestAutoUnwrapUVErrors(t *testing.T) {
+	compMesh := NewMesh()
+	if err := compMesh.AutoUnwrapUV(UVUnwrapBox); err == nil {
+		t.Errorf("A mesh without data should fail to unwrap.")
+	}
+
+	comp, _ := CreatePrimitive(PrimCube, DefaultPrimitiveParams())
+	if err := comp.Meshes[0].AutoUnwrapUV("planar"); err == nil {
+		t.Errorf("An unknown unwrap method should fail.")
+	}
+}
@@ -78,6 +78,9 @@ type GraphicsProvider interface {
 	// BufferData creates a new data store for the bound buffer object.
 	BufferData(target Enum, size int, data unsafe.Pointer, usage Enum)
 
+	// BufferSubData updates a subset of the data store for the bound buffer object.
+	BufferSubData(target Enum, offset int, size int, data unsafe.Pointer)
+
 	// CheckFramebufferStatus checks the completeness status of a framebuffer
 	CheckFramebufferStatus(target Enum) Enum
 
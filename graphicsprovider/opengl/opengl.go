@@ -97,6 +97,11 @@ func (impl *GraphicsImpl) BufferData(target graphics.Enum, size int, data unsafe
 	gl.BufferData(uint32(target), size, data, uint32(usage))
 }
 
+// BufferSubData updates a subset of the data store for the bound buffer object.
+func (impl *GraphicsImpl) BufferSubData(target graphics.Enum, offset int, size int, data unsafe.Pointer) {
+	gl.BufferSubData(uint32(target), offset, size, data)
+}
+
 // CheckFramebufferStatus checks the completeness status of a framebuffer
 func (impl *GraphicsImpl) CheckFramebufferStatus(target graphics.Enum) graphics.Enum {
 	return graphics.Enum(gl.CheckFramebufferStatus(uint32(target)))
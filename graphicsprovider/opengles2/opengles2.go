@@ -90,6 +90,11 @@ func (impl *GraphicsImpl) BufferData(target graphics.Enum, size int, data unsafe
 	gles.BufferData(gles.Enum(target), gles.SizeiPtr(size), gles.Void(data), gles.Enum(usage))
 }
 
+// BufferSubData updates a subset of the data store for the bound buffer object.
+func (impl *GraphicsImpl) BufferSubData(target graphics.Enum, offset int, size int, data unsafe.Pointer) {
+	gles.BufferSubData(gles.Enum(target), gles.IntPtr(offset), gles.SizeiPtr(size), gles.Void(data))
+}
+
 // CheckFramebufferStatus checks the completeness status of a framebuffer
 func (impl *GraphicsImpl) CheckFramebufferStatus(target graphics.Enum) graphics.Enum {
 	return graphics.Enum(gles.CheckFramebufferStatus(gles.Enum(target)))
@@ -15,6 +15,7 @@ package forward
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	mgl "github.com/go-gl/mathgl/mgl32"
@@ -74,6 +75,19 @@ type ShadowMap struct {
 	// Updated with UpdateShadowMapData().
 	BiasedMatrix mgl.Mat4
 
+	// Atlas is the ShadowAtlas the shadow map is rendered into or nil if the
+	// shadow map has a texture of its own.
+	Atlas *ShadowAtlas
+
+	// AtlasUVOffset is the texture coordinate of the shadow map's slot in the Atlas.
+	AtlasUVOffset mgl.Vec2
+
+	// AtlasUVScale is the size of the shadow map's slot in the Atlas in texture coordinates.
+	AtlasUVScale mgl.Vec2
+
+	// atlasLightIndex is the light index the Atlas slot was assigned for.
+	atlasLightIndex int
+
 	// owner is the owning renderer
 	owner *ForwardRenderer
 }
@@ -81,6 +95,12 @@ type ShadowMap struct {
 // Destroy deallocates any data being held onto by the ShadowMap that is not
 // controlled by the Go GC.
 func (shady *ShadowMap) Destroy() {
+	// atlas textures are shared so only give up the slot
+	if shady.Atlas != nil {
+		shady.Atlas.ReleaseShadowAtlasSlot(shady.atlasLightIndex)
+		return
+	}
+
 	// delete the texture associated with the shadow map
 	shady.owner.GetGraphics().DeleteTexture(shady.Texture)
 }
@@ -117,6 +137,18 @@ type Light struct {
 	// Strength is the scale factor on the light strength.
 	Strength float32
 
+	// InnerAngle is the angle in degrees from the Direction of a spot light
+	// within which it shines at full strength.
+	InnerAngle float32
+
+	// OuterAngle is the angle in degrees from the Direction of a spot light
+	// where its light falls off completely. Zero means the light is not a spot light.
+	OuterAngle float32
+
+	// Range is the maximum distance a spot light reaches which is also used
+	// as the far distance of its shadow map.
+	Range float32
+
 	// ShadowMap is the texture, and other data, used to render
 	// shadows casted by the light. This member is nil when
 	// the light does not cast shadows.
@@ -170,6 +202,60 @@ func (l *Light) CreateShadowMap(textureSize int32, near float32, far float32, di
 	gfx.BindTexture(graphics.TEXTURE_2D, 0)
 }
 
+// CreateAtlasShadowMap sets up the light to render its shadows into a slot of
+// the ShadowAtlas that is assigned for the light index. Spot lights get a
+// perspective projection that covers their OuterAngle; other lights use the
+// same projection as CreateShadowMap(). Returns false if no slot could be assigned.
+func (l *Light) CreateAtlasShadowMap(atlas *ShadowAtlas, lightIndex int, near float32, far float32, dir mgl.Vec3) bool {
+	// if there was already a shadow map, destroy it
+	if l.ShadowMap != nil {
+		l.ShadowMap.Destroy()
+		l.ShadowMap = nil
+	}
+
+	uvOffset, uvScale, ok := atlas.AssignShadowAtlasSlot(lightIndex)
+	if !ok {
+		return false
+	}
+
+	l.ShadowMap = l.owner.NewShadowMap()
+	l.ShadowMap.Near = near
+	l.ShadowMap.Far = far
+	if l.OuterAngle > 0.0 {
+		l.ShadowMap.Projection = mgl.Perspective(mgl.DegToRad(l.OuterAngle*2.0), 1.0, near, far)
+	} else {
+		factor := float32(0.5)
+		l.ShadowMap.Projection = mgl.Frustum(-factor, factor, -factor, factor, near, far)
+	}
+
+	l.ShadowMap.TextureSize = atlas.SlotSize
+	l.ShadowMap.Direction = dir
+	l.ShadowMap.Texture = atlas.Texture
+	l.ShadowMap.Atlas = atlas
+	l.ShadowMap.AtlasUVOffset = uvOffset
+	l.ShadowMap.AtlasUVScale = uvScale
+	l.ShadowMap.atlasLightIndex = lightIndex
+	atlas.setSlotLight(lightIndex, l)
+
+	return true
+}
+
+// removeAtlasShadowMap is called when the light's slot in the atlas has been
+// given to another light. The shadow map is detached from the atlas so that it
+// no longer draws into or releases the slot and the light stops casting shadows.
+func (l *Light) removeAtlasShadowMap(atlas *ShadowAtlas) {
+	if l.ShadowMap == nil || l.ShadowMap.Atlas != atlas {
+		return
+	}
+
+	l.ShadowMap.Atlas = nil
+	l.ShadowMap.Texture = 0
+	l.ShadowMap.AtlasUVOffset = mgl.Vec2{}
+	l.ShadowMap.AtlasUVScale = mgl.Vec2{}
+	l.ShadowMap.atlasLightIndex = -1
+	l.ShadowMap = nil
+}
+
 // UpdateShadowMapData updates a shadow maps internal structures based on data
 // from the light.
 func (l *Light) UpdateShadowMapData() {
@@ -189,6 +275,12 @@ func (l *Light) UpdateShadowMapData() {
 
 	// update the shadow biased matrix
 	l.ShadowMap.BiasedMatrix = shadowBiasMat.Mul4(l.ShadowMap.ViewProjMatrix)
+
+	// shadow maps in an atlas need their texture coordinates moved into the slot
+	if l.ShadowMap.Atlas != nil {
+		atlasMat := getAtlasUVMatrix(l.ShadowMap.AtlasUVOffset, l.ShadowMap.AtlasUVScale)
+		l.ShadowMap.BiasedMatrix = atlasMat.Mul4(l.ShadowMap.BiasedMatrix)
+	}
 }
 
 // ForwardRenderer is a forward-rendering style renderer, meaning that when
@@ -215,6 +307,10 @@ type ForwardRenderer struct {
 	// currentShadowPassLight is the light currently enabled for shadow mapping
 	currentShadowPassLight *Light
 
+	// drawCallCount is the number of draw calls made since the last time
+	// ResetDrawCallCount() was called.
+	drawCallCount int
+
 	// gfx is the underlying graphics implementation for the renderer
 	gfx graphics.GraphicsProvider
 }
@@ -264,6 +360,30 @@ func (fr *ForwardRenderer) NewPointLight(location mgl.Vec3) *Light {
 	return light
 }
 
+// NewSpotLight creates a new light and sets it up to be a spot light at the
+// location pointing in the direction specified. The light is at full strength
+// within innerAngle degrees of the direction and falls off by outerAngle degrees.
+func (fr *ForwardRenderer) NewSpotLight(location mgl.Vec3, dir mgl.Vec3, innerAngle, outerAngle, lightRange float32) *Light {
+	light := fr.NewPointLight(location)
+	light.Direction = dir
+	light.InnerAngle = innerAngle
+	light.OuterAngle = outerAngle
+	light.Range = lightRange
+	return light
+}
+
+// getSpotLightUniforms returns the cosines of the inner and outer cone angles
+// and the range of a spot light for the shader uniforms. Lights that are not
+// spot lights get cosines of -1 and a range of 0 which disable both falloffs.
+func getSpotLightUniforms(l *Light) (cosInner, cosOuter, lightRange float32) {
+	if l.OuterAngle <= 0.0 {
+		return -1.0, -1.0, 0.0
+	}
+	cosInner = float32(math.Cos(float64(mgl.DegToRad(l.InnerAngle))))
+	cosOuter = float32(math.Cos(float64(mgl.DegToRad(l.OuterAngle))))
+	return cosInner, cosOuter, l.Range
+}
+
 // NewDirectionalLight creates a new light and sets it up to be a directional light.
 func (fr *ForwardRenderer) NewDirectionalLight(dir mgl.Vec3) *Light {
 	light := fr.NewLight()
@@ -318,6 +438,18 @@ func (fr *ForwardRenderer) EndRenderFrame() {
 	// nothing to do
 }
 
+// GetDrawCallCount returns the number of draw calls made since the last time
+// ResetDrawCallCount() was called.
+func (fr *ForwardRenderer) GetDrawCallCount() int {
+	return fr.drawCallCount
+}
+
+// ResetDrawCallCount sets the draw call count back to zero. This is
+// typically done at the start of every frame.
+func (fr *ForwardRenderer) ResetDrawCallCount() {
+	fr.drawCallCount = 0
+}
+
 // GetActiveLightCount counts the number of *Light set in
 // the ForwardRenderer's ActiveLights array until a nil is hit.
 // NOTE: Obviously requires ActiveLights to be packed sequentially.
@@ -394,6 +526,18 @@ func (fr *ForwardRenderer) EnableShadowMappingLight(l *Light) {
 	fr.currentShadowPassLight = l
 	l.UpdateShadowMapData()
 	fr.gfx.FramebufferTexture2D(graphics.FRAMEBUFFER, graphics.DEPTH_ATTACHMENT, graphics.TEXTURE_2D, l.ShadowMap.Texture, 0)
+
+	// only clear and draw to the light's slot when rendering into an atlas
+	if l.ShadowMap.Atlas != nil {
+		x, y, w, h := l.ShadowMap.Atlas.getViewportForUV(l.ShadowMap.AtlasUVOffset)
+		fr.gfx.Enable(graphics.SCISSOR_TEST)
+		fr.gfx.Scissor(x, y, w, h)
+		fr.gfx.Clear(graphics.DEPTH_BUFFER_BIT)
+		fr.gfx.Disable(graphics.SCISSOR_TEST)
+		fr.gfx.Viewport(x, y, w, h)
+		return
+	}
+
 	fr.gfx.Clear(graphics.DEPTH_BUFFER_BIT)
 	fr.gfx.Viewport(0, 0, l.ShadowMap.TextureSize, l.ShadowMap.TextureSize)
 }
@@ -457,6 +601,27 @@ func (fr *ForwardRenderer) chainedBinder(renderer renderer.Renderer, r *fizzle.R
 				gfx.Uniform1f(shaderLightStrength, light.Strength)
 			}
 
+			// spot light cones are passed as cosines so shaders can compare them
+			// against the dot product of the light direction. the uniforms are
+			// always set so that a light that isn't a spot doesn't keep the cone
+			// of a spot light drawn before it; a cosine of -1 means no cone and
+			// a range of 0 means no range.
+			spotCosInner, spotCosOuter, lightRange := getSpotLightUniforms(light)
+			shaderLightSpotInner := shader.GetUniformLocation(fmt.Sprintf("LIGHT_SPOT_COS_INNER[%d]", lightI))
+			if shaderLightSpotInner >= 0 {
+				gfx.Uniform1f(shaderLightSpotInner, spotCosInner)
+			}
+
+			shaderLightSpotOuter := shader.GetUniformLocation(fmt.Sprintf("LIGHT_SPOT_COS_OUTER[%d]", lightI))
+			if shaderLightSpotOuter >= 0 {
+				gfx.Uniform1f(shaderLightSpotOuter, spotCosOuter)
+			}
+
+			shaderLightRange := shader.GetUniformLocation(fmt.Sprintf("LIGHT_RANGE[%d]", lightI))
+			if shaderLightRange >= 0 {
+				gfx.Uniform1f(shaderLightRange, lightRange)
+			}
+
 			shaderShadowMaps := shader.GetUniformLocation(fmt.Sprintf("SHADOW_MAPS[%d]", lightI))
 			if shaderShadowMaps >= 0 {
 				///* There have been problems in the past on Intel drivers on Mac OS if all of the
@@ -521,6 +686,7 @@ func (fr *ForwardRenderer) DrawRenderable(r *fizzle.Renderable, binder renderer.
 	if binder != nil {
 		binders = append(binders, binder)
 	}
+	fr.drawCallCount++
 	renderer.BindAndDraw(fr, r, r.Material.Shader, binders, perspective, view, camera, graphics.TRIANGLES)
 }
 
@@ -547,6 +713,7 @@ func (fr *ForwardRenderer) DrawRenderableWithShader(r *fizzle.Renderable, shader
 	if binder != nil {
 		binders = append(binders, binder)
 	}
+	fr.drawCallCount++
 	renderer.BindAndDraw(fr, r, shader, binders, perspective, view, camera, graphics.TRIANGLES)
 }
 
@@ -572,5 +739,6 @@ func (fr *ForwardRenderer) DrawLines(r *fizzle.Renderable, shader *fizzle.Render
 	if binder != nil {
 		binders = append(binders, binder)
 	}
+	fr.drawCallCount++
 	renderer.BindAndDraw(fr, r, shader, binders, perspective, view, camera, graphics.LINES)
 }
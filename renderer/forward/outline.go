@@ -0,0 +1,55 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package forward
+
+import (
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/tbogdala/fizzle"
+	graphics "github.com/tbogdala/fizzle/graphicsprovider"
+	renderer "github.com/tbogdala/fizzle/renderer"
+)
+
+const (
+	// MinOutlineThickness is the smallest outline thickness DrawOutline will use.
+	MinOutlineThickness = 0.0
+
+	// MaxOutlineThickness is the largest outline thickness DrawOutline will use.
+	MaxOutlineThickness = 0.25
+)
+
+// ClampOutlineThickness limits the thickness to the range supported by
+// DrawOutline: [MinOutlineThickness..MaxOutlineThickness].
+func ClampOutlineThickness(thickness float32) float32 {
+	if thickness < MinOutlineThickness {
+		return MinOutlineThickness
+	} else if thickness > MaxOutlineThickness {
+		return MaxOutlineThickness
+	}
+	return thickness
+}
+
+// DrawOutline draws the first pass of an outline highlight for the Renderable
+// using a shader created by CreateEdgeDetectionShader(). The mesh is pushed out
+// along its normals by thickness, in the mesh's local units, and only the
+// back faces are drawn in the outline color. Drawing the Renderable normally
+// afterwards covers everything but the edges of this shell.
+func (fr *ForwardRenderer) DrawOutline(r *fizzle.Renderable, shader *fizzle.RenderShader, outlineColor mgl.Vec4, outlineThickness float32,
+	perspective mgl.Mat4, view mgl.Mat4, camera fizzle.Camera) {
+	thickness := ClampOutlineThickness(outlineThickness)
+	binder := func(_ renderer.Renderer, _ *fizzle.Renderable, shader *fizzle.RenderShader, _ *int32) {
+		shaderOutlineColor := shader.GetUniformLocation("OUTLINE_COLOR")
+		if shaderOutlineColor >= 0 {
+			fr.gfx.Uniform4f(shaderOutlineColor, outlineColor[0], outlineColor[1], outlineColor[2], outlineColor[3])
+		}
+
+		shaderOutlineThickness := shader.GetUniformLocation("OUTLINE_THICKNESS")
+		if shaderOutlineThickness >= 0 {
+			fr.gfx.Uniform1f(shaderOutlineThickness, thickness)
+		}
+	}
+
+	fr.gfx.CullFace(graphics.FRONT)
+	fr.DrawRenderableWithShader(r, shader, binder, perspective, view, camera)
+	fr.gfx.CullFace(graphics.BACK)
+}
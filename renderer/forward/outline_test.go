@@ -0,0 +1,64 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package forward
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tbogdala/fizzle"
+)
+
+func TestClampOutlineThickness(t *testing.T) {
+	tests := []struct {
+		thickness, expected float32
+	}{
+		{0.1, 0.1},
+		{MinOutlineThickness, MinOutlineThickness},
+		{MaxOutlineThickness, MaxOutlineThickness},
+		{-1.0, MinOutlineThickness},
+		{MaxOutlineThickness + 0.01, MaxOutlineThickness},
+		{100.0, MaxOutlineThickness},
+	}
+
+	for _, test := range tests {
+		clamped := ClampOutlineThickness(test.thickness)
+		if clamped != test.expected {
+			t.Errorf("ClampOutlineThickness(%f) returned %f; expected %f", test.thickness, clamped, test.expected)
+		}
+	}
+}
+
+func TestEdgeDetectionShaderUniforms(t *testing.T) {
+	tests := []struct {
+		name, source, declaration string
+	}{
+		{"edgeDetectionShaderV", edgeDetectionShaderV, "uniform mat4 MVP_MATRIX;"},
+		{"edgeDetectionShaderV", edgeDetectionShaderV, "uniform float OUTLINE_THICKNESS;"},
+		{"edgeDetectionShaderV", edgeDetectionShaderV, "in vec3 VERTEX_NORMAL;"},
+		{"edgeDetectionShaderF", edgeDetectionShaderF, "uniform vec4 OUTLINE_COLOR;"},
+	}
+
+	for _, test := range tests {
+		if !strings.Contains(test.source, test.declaration) {
+			t.Errorf("%s does not declare %q", test.name, test.declaration)
+		}
+	}
+}
+
+func TestCreateEdgeDetectionShader(t *testing.T) {
+	if fizzle.GetGraphics() == nil {
+		t.Skip("no OpenGL context is available to compile the shader")
+	}
+
+	shader, err := CreateEdgeDetectionShader()
+	if err != nil {
+		t.Fatalf("CreateEdgeDetectionShader returned an error: %v", err)
+	}
+	defer shader.Destroy()
+
+	if err := shader.AssertUniformsExist("OUTLINE_COLOR", "OUTLINE_THICKNESS"); err != nil {
+		t.Errorf("AssertUniformsExist returned an error for the edge detection shader: %v", err)
+	}
+}
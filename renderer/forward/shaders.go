@@ -67,9 +67,10 @@ const (
     		vec3 incidence;
     		float attenuation = LIGHT_STRENGTH[i];
     		vec3 light_direction = LIGHT_DIRECTION[i]; // in world space
+    		bool is_spot = LIGHT_SPOT_COS_OUTER[i] > -1.0;
 
-    		if (light_direction.x == 0.0 && light_direction.y == 0.0 && light_direction.z == 0.0) {
-    			// point light
+    		if (is_spot || (light_direction.x == 0.0 && light_direction.y == 0.0 && light_direction.z == 0.0)) {
+    			// point or spot light
     			light_direction = LIGHT_POSITION[i] - v_model;
     			float distance = length(light_direction);
 
@@ -80,6 +81,18 @@ const (
 
     			light_direction = light_direction / distance;
     			incidence = light_direction;
+
+    			// fade out spot lights between the inner and outer cone
+    			if (is_spot) {
+    				float spot_cos = dot(-incidence, normalize(LIGHT_DIRECTION[i]));
+    				float cone_width = max(LIGHT_SPOT_COS_INNER[i] - LIGHT_SPOT_COS_OUTER[i], 0.0001);
+    				attenuation *= clamp((spot_cos - LIGHT_SPOT_COS_OUTER[i]) / cone_width, 0.0, 1.0);
+    			}
+
+    			// fade out lights with a range as they reach it
+    			if (LIGHT_RANGE[i] > 0.0) {
+    				attenuation *= clamp(1.0 - distance / LIGHT_RANGE[i], 0.0, 1.0);
+    			}
     	  } else {
     			// directional light
     			light_direction = normalize(light_direction);
@@ -188,6 +201,9 @@ const (
     uniform float LIGHT_LINEAR_ATTENUATION[MAX_LIGHTS];
     uniform float LIGHT_QUADRATIC_ATTENUATION[MAX_LIGHTS];
     uniform float LIGHT_STRENGTH[MAX_LIGHTS];
+    uniform float LIGHT_SPOT_COS_INNER[MAX_LIGHTS];
+    uniform float LIGHT_SPOT_COS_OUTER[MAX_LIGHTS];
+    uniform float LIGHT_RANGE[MAX_LIGHTS];
     uniform int LIGHT_COUNT;
     uniform int SHADOW_COUNT;
 
@@ -326,6 +342,9 @@ const (
     uniform float LIGHT_LINEAR_ATTENUATION[MAX_LIGHTS];
     uniform float LIGHT_QUADRATIC_ATTENUATION[MAX_LIGHTS];
     uniform float LIGHT_STRENGTH[MAX_LIGHTS];
+    uniform float LIGHT_SPOT_COS_INNER[MAX_LIGHTS];
+    uniform float LIGHT_SPOT_COS_OUTER[MAX_LIGHTS];
+    uniform float LIGHT_RANGE[MAX_LIGHTS];
     uniform int LIGHT_COUNT;
     uniform int SHADOW_COUNT;
 
@@ -488,6 +507,45 @@ const (
 			}
 			`
 
+	/*
+
+	    ____            _     _   _
+	   / __ \          | |   | | (_)
+	  | |  | |  _   _  | |_  | |  _   _ __     ___
+	  | |  | | | | | | | __| | | | | | '_ \   / _ \
+	  | |__| | | |_| | | |_  | | | | | | | | |  __/
+	   \____/   \__,_|  \__| |_| |_| |_| |_|  \___|
+
+	*/
+
+	edgeDetectionShaderV = `#version 330
+    precision highp float;
+
+    uniform mat4 MVP_MATRIX;
+    uniform float OUTLINE_THICKNESS;
+
+    in vec3 VERTEX_POSITION;
+    in vec3 VERTEX_NORMAL;
+
+    void main(void) {
+    	/* push the vertex out along its normal to make a shell around the mesh */
+    	vec3 inflated = VERTEX_POSITION + normalize(VERTEX_NORMAL) * OUTLINE_THICKNESS;
+    	gl_Position = MVP_MATRIX * vec4(inflated, 1.0);
+    }
+    `
+
+	edgeDetectionShaderF = `#version 330
+    precision highp float;
+
+    uniform vec4 OUTLINE_COLOR;
+
+    out vec4 frag_color;
+
+    void main (void) {
+    	frag_color = OUTLINE_COLOR;
+    }
+    `
+
 	/*
 	   _____   _                   _                                                     _____
 	   / ____| | |                 | |                                                   / ____|
@@ -555,6 +613,14 @@ func CreateShadowmapGeneratorShader() (*fizzle.RenderShader, error) {
 	return fizzle.LoadShaderProgram(shadowmapGeneratorV, shadowmapGeneratorF, nil)
 }
 
+// CreateEdgeDetectionShader creates a new shader object using the built in
+// outline shader that draws a mesh pushed out along its normals in a flat color.
+// It is meant to be used with ForwardRenderer.DrawOutline() which sets the
+// OUTLINE_COLOR and OUTLINE_THICKNESS uniforms.
+func CreateEdgeDetectionShader() (*fizzle.RenderShader, error) {
+	return fizzle.LoadShaderProgram(edgeDetectionShaderV, edgeDetectionShaderF, nil)
+}
+
 // CreateDiffuseUnlitShader creates a new shader object using the built
 // in diffuse texture shader that is unlit (no lighting calculated).
 func CreateDiffuseUnlitShader() (*fizzle.RenderShader, error) {
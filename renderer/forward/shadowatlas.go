@@ -0,0 +1,217 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package forward
+
+import (
+	mgl "github.com/go-gl/mathgl/mgl32"
+	graphics "github.com/tbogdala/fizzle/graphicsprovider"
+)
+
+const (
+	// DefaultShadowAtlasSize is the default size of the shadow atlas texture.
+	DefaultShadowAtlasSize = 4096
+
+	// DefaultShadowAtlasSlotSize is the default size of each shadow map
+	// packed into the shadow atlas.
+	DefaultShadowAtlasSlotSize = 512
+)
+
+// shadowAtlasSlot tracks which light is using a region of the shadow atlas.
+type shadowAtlasSlot struct {
+	// lightIndex is the index of the light using the slot or -1 if it's free.
+	lightIndex int
+
+	// lastUsed is the value of the atlas' use counter when the slot was
+	// last assigned and is used to evict the least recently used slot.
+	lastUsed uint64
+
+	// light is the light whose shadow map was created in the slot by
+	// CreateAtlasShadowMap so that it can lose the shadow map if the slot
+	// gets evicted. This is nil for slots assigned directly.
+	light *Light
+}
+
+// ShadowAtlas packs the shadow maps of multiple lights into a grid of slots
+// in a single large depth texture so that point and spot lights can cast
+// shadows without each needing a texture of their own.
+type ShadowAtlas struct {
+	// Texture is the depth texture the shadow maps are rendered into.
+	Texture graphics.Texture
+
+	// TextureSize is the width and height of the texture in memory.
+	TextureSize int32
+
+	// SlotSize is the width and height of each shadow map in the atlas.
+	SlotSize int32
+
+	// slots are the regions of the atlas in row-major order.
+	slots []shadowAtlasSlot
+
+	// useCounter is incremented every time a slot is assigned.
+	useCounter uint64
+
+	// evictedLight is the light index that lost its slot in the last call
+	// to AssignShadowAtlasSlot or -1 if no light did.
+	evictedLight int
+
+	// owner is the owning renderer
+	owner *ForwardRenderer
+}
+
+// newShadowAtlasSlots creates the slot tracking for an atlas of the texture
+// size specified that is split up into slots of slotSize.
+func newShadowAtlasSlots(textureSize, slotSize int32) []shadowAtlasSlot {
+	if slotSize <= 0 || slotSize > textureSize {
+		return nil
+	}
+
+	slotsPerRow := textureSize / slotSize
+	slots := make([]shadowAtlasSlot, slotsPerRow*slotsPerRow)
+	for i := range slots {
+		slots[i].lightIndex = -1
+	}
+	return slots
+}
+
+// NewShadowAtlas allocates a depth texture of textureSize that holds as many
+// shadow maps of slotSize as will fit.
+func (fr *ForwardRenderer) NewShadowAtlas(textureSize, slotSize int32) *ShadowAtlas {
+	atlas := new(ShadowAtlas)
+	atlas.owner = fr
+	atlas.TextureSize = textureSize
+	atlas.SlotSize = slotSize
+	atlas.slots = newShadowAtlasSlots(textureSize, slotSize)
+	atlas.evictedLight = -1
+
+	gfx := fr.GetGraphics()
+	atlas.Texture = gfx.GenTexture()
+	gfx.ActiveTexture(graphics.TEXTURE0)
+	gfx.BindTexture(graphics.TEXTURE_2D, atlas.Texture)
+	gfx.TexImage2D(graphics.TEXTURE_2D, 0, graphics.DEPTH_COMPONENT32, textureSize, textureSize, 0, graphics.DEPTH_COMPONENT, graphics.UNSIGNED_INT, nil, 0)
+	gfx.TexParameteri(graphics.TEXTURE_2D, graphics.TEXTURE_MAG_FILTER, graphics.LINEAR)
+	gfx.TexParameteri(graphics.TEXTURE_2D, graphics.TEXTURE_MIN_FILTER, graphics.LINEAR)
+
+	// points outside of the atlas are projected to be not in shadow, but points
+	// outside of a slot will sample the neighboring slot so lights should make
+	// sure their projections cover what they light.
+	shadowmapBorder := mgl.Vec4{1.0, 1.0, 1.0, 1.0}
+	gfx.TexParameterfv(graphics.TEXTURE_2D, graphics.TEXTURE_BORDER_COLOR, &shadowmapBorder[0])
+	gfx.TexParameteri(graphics.TEXTURE_2D, graphics.TEXTURE_WRAP_S, graphics.CLAMP_TO_BORDER)
+	gfx.TexParameteri(graphics.TEXTURE_2D, graphics.TEXTURE_WRAP_T, graphics.CLAMP_TO_BORDER)
+	gfx.TexParameteri(graphics.TEXTURE_2D, graphics.TEXTURE_COMPARE_MODE, graphics.COMPARE_REF_TO_TEXTURE)
+
+	// a safety unbind
+	gfx.BindTexture(graphics.TEXTURE_2D, 0)
+
+	return atlas
+}
+
+// Destroy deallocates the atlas texture.
+func (atlas *ShadowAtlas) Destroy() {
+	atlas.owner.GetGraphics().DeleteTexture(atlas.Texture)
+}
+
+// SlotCount returns the number of shadow maps the atlas can hold.
+func (atlas *ShadowAtlas) SlotCount() int {
+	return len(atlas.slots)
+}
+
+// AssignShadowAtlasSlot allocates a slot in the atlas for the light index and
+// returns the offset and scale that transform the light's shadow map texture
+// coordinates into the slot. If the light already has a slot, it is reused.
+// If all slots are in use, the least recently assigned slot is evicted and
+// given to the light; EvictedLight returns the light index that lost it. A
+// light that got its shadow map from CreateAtlasShadowMap has the shadow map
+// removed when its slot is evicted, so it stops casting shadows until it gets
+// a new slot. ok is false if the atlas has no slots.
+func (atlas *ShadowAtlas) AssignShadowAtlasSlot(lightIndex int) (uvOffset, uvScale mgl.Vec2, ok bool) {
+	atlas.evictedLight = -1
+	if len(atlas.slots) == 0 || lightIndex < 0 {
+		return uvOffset, uvScale, false
+	}
+
+	chosen := -1
+	for i, slot := range atlas.slots {
+		if slot.lightIndex == lightIndex {
+			chosen = i
+			break
+		}
+		if chosen < 0 || slot.lastUsed < atlas.slots[chosen].lastUsed {
+			// free slots always have the lowest use value
+			chosen = i
+		}
+	}
+
+	slot := &atlas.slots[chosen]
+	if slot.lightIndex >= 0 && slot.lightIndex != lightIndex {
+		atlas.evictedLight = slot.lightIndex
+		if slot.light != nil {
+			slot.light.removeAtlasShadowMap(atlas)
+		}
+		slot.light = nil
+	}
+
+	atlas.useCounter++
+	slot.lightIndex = lightIndex
+	slot.lastUsed = atlas.useCounter
+
+	uvOffset, uvScale = atlas.getSlotUVTransform(chosen)
+	return uvOffset, uvScale, true
+}
+
+// EvictedLight returns the light index that lost its slot in the last call to
+// AssignShadowAtlasSlot or -1 if no light did.
+func (atlas *ShadowAtlas) EvictedLight() int {
+	return atlas.evictedLight
+}
+
+// ReleaseShadowAtlasSlot frees the slot assigned to the light index, if any.
+func (atlas *ShadowAtlas) ReleaseShadowAtlasSlot(lightIndex int) {
+	for i, slot := range atlas.slots {
+		if slot.lightIndex == lightIndex {
+			atlas.slots[i].lightIndex = -1
+			atlas.slots[i].lastUsed = 0
+			atlas.slots[i].light = nil
+		}
+	}
+}
+
+// setSlotLight records the light whose shadow map uses the slot assigned to
+// the light index.
+func (atlas *ShadowAtlas) setSlotLight(lightIndex int, l *Light) {
+	for i, slot := range atlas.slots {
+		if slot.lightIndex == lightIndex {
+			atlas.slots[i].light = l
+		}
+	}
+}
+
+// getSlotUVTransform returns the texture coordinate offset and scale for the slot.
+func (atlas *ShadowAtlas) getSlotUVTransform(slotIndex int) (mgl.Vec2, mgl.Vec2) {
+	x, y := atlas.getSlotPixelOffset(slotIndex)
+	size := float32(atlas.TextureSize)
+	scale := float32(atlas.SlotSize) / size
+	return mgl.Vec2{float32(x) / size, float32(y) / size}, mgl.Vec2{scale, scale}
+}
+
+// getSlotPixelOffset returns the lower-left corner of the slot in pixels.
+func (atlas *ShadowAtlas) getSlotPixelOffset(slotIndex int) (int32, int32) {
+	slotsPerRow := int(atlas.TextureSize / atlas.SlotSize)
+	x := int32(slotIndex%slotsPerRow) * atlas.SlotSize
+	y := int32(slotIndex/slotsPerRow) * atlas.SlotSize
+	return x, y
+}
+
+// getViewportForUV converts a slot's texture coordinate offset back into the
+// pixel rectangle of the slot.
+func (atlas *ShadowAtlas) getViewportForUV(uvOffset mgl.Vec2) (x, y, w, h int32) {
+	size := float32(atlas.TextureSize)
+	return int32(uvOffset[0]*size + 0.5), int32(uvOffset[1]*size + 0.5), atlas.SlotSize, atlas.SlotSize
+}
+
+// getAtlasUVMatrix returns a matrix that moves texture coordinates in the range
+// [0..1] into the slot described by the offset and scale.
+func getAtlasUVMatrix(uvOffset, uvScale mgl.Vec2) mgl.Mat4 {
+	return mgl.Translate3D(uvOffset[0], uvOffset[1], 0.0).Mul4(mgl.Scale3D(uvScale[0], uvScale[1], 1.0))
+}
@@ -0,0 +1,114 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package forward
+
+import (
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// newTestShadowAtlas creates an atlas without a texture so that the slot
+// assignment can be tested without a graphics context.
+func newTestShadowAtlas(textureSize, slotSize int32) *ShadowAtlas {
+	atlas := new(ShadowAtlas)
+	atlas.TextureSize = textureSize
+	atlas.SlotSize = slotSize
+	atlas.slots = newShadowAtlasSlots(textureSize, slotSize)
+	atlas.evictedLight = -1
+	return atlas
+}
+
+func TestAssignShadowAtlasSlot(t *testing.T) {
+	atlas := newTestShadowAtlas(1024, 512)
+	if atlas.SlotCount() != 4 {
+		t.Fatalf("The atlas has %d slots; expected 4", atlas.SlotCount())
+	}
+
+	expectedOffsets := []mgl.Vec2{{0, 0}, {0.5, 0}, {0, 0.5}, {0.5, 0.5}}
+	for i, expected := range expectedOffsets {
+		uvOffset, uvScale, ok := atlas.AssignShadowAtlasSlot(i)
+		if evicted := atlas.EvictedLight(); !ok || evicted != -1 {
+			t.Fatalf("Assigning light %d to a free slot returned ok=%v evicted=%d", i, ok, evicted)
+		}
+		if uvOffset != expected || uvScale != (mgl.Vec2{0.5, 0.5}) {
+			t.Errorf("Light %d got the slot at %v scaled %v; expected %v scaled 0.5", i, uvOffset, uvScale, expected)
+		}
+	}
+
+	// assigning a light again keeps its slot and marks it as recently used
+	uvOffset, _, _ := atlas.AssignShadowAtlasSlot(0)
+	evicted := atlas.EvictedLight()
+	if uvOffset != expectedOffsets[0] || evicted != -1 {
+		t.Errorf("Light 0 was moved to %v (evicted %d) instead of keeping its slot.", uvOffset, evicted)
+	}
+
+	// the atlas is full so the least recently used slot, light 1's, is evicted
+	uvOffset, _, ok := atlas.AssignShadowAtlasSlot(4)
+	evicted = atlas.EvictedLight()
+	if !ok || evicted != 1 || uvOffset != expectedOffsets[1] {
+		t.Errorf("Light 4 got %v evicting %d; expected %v evicting 1", uvOffset, evicted, expectedOffsets[1])
+	}
+
+	// releasing a slot frees it for the next light without an eviction
+	atlas.ReleaseShadowAtlasSlot(2)
+	uvOffset, _, _ = atlas.AssignShadowAtlasSlot(5)
+	evicted = atlas.EvictedLight()
+	if evicted != -1 || uvOffset != expectedOffsets[2] {
+		t.Errorf("Light 5 got %v evicting %d; expected the released slot at %v", uvOffset, evicted, expectedOffsets[2])
+	}
+
+	if _, _, ok := atlas.AssignShadowAtlasSlot(-1); ok {
+		t.Errorf("A negative light index should not get a slot.")
+	}
+	if _, _, ok := newTestShadowAtlas(256, 512).AssignShadowAtlasSlot(0); ok {
+		t.Errorf("An atlas without slots should not assign one.")
+	}
+}
+
+func TestAssignShadowAtlasSlotEvictsShadowMap(t *testing.T) {
+	atlas := newTestShadowAtlas(512, 512)
+
+	// set up the light the same way CreateAtlasShadowMap does
+	evictedLight := new(Light)
+	uvOffset, uvScale, _ := atlas.AssignShadowAtlasSlot(0)
+	evictedLight.ShadowMap = new(ShadowMap)
+	evictedLight.ShadowMap.Atlas = atlas
+	evictedLight.ShadowMap.AtlasUVOffset = uvOffset
+	evictedLight.ShadowMap.AtlasUVScale = uvScale
+	evictedLight.ShadowMap.atlasLightIndex = 0
+	atlas.setSlotLight(0, evictedLight)
+	oldShadowMap := evictedLight.ShadowMap
+
+	_, _, ok := atlas.AssignShadowAtlasSlot(1)
+	evicted := atlas.EvictedLight()
+	if !ok || evicted != 0 {
+		t.Fatalf("Light 1 should have evicted light 0; got ok=%v evicted=%d", ok, evicted)
+	}
+	if evictedLight.ShadowMap != nil {
+		t.Errorf("The evicted light still has its shadow map.")
+	}
+	if oldShadowMap.Atlas != nil {
+		t.Errorf("The evicted shadow map still references the atlas.")
+	}
+
+	// releasing the evicted light's old index must not free light 1's slot
+	atlas.ReleaseShadowAtlasSlot(0)
+	atlas.AssignShadowAtlasSlot(2)
+	if evicted := atlas.EvictedLight(); evicted != 1 {
+		t.Errorf("Light 1 should still have had the slot; evicted %d", evicted)
+	}
+}
+
+func TestGetSpotLightUniforms(t *testing.T) {
+	cosInner, cosOuter, lightRange := getSpotLightUniforms(&Light{Range: 10.0})
+	if cosInner != -1.0 || cosOuter != -1.0 || lightRange != 0.0 {
+		t.Errorf("A light that isn't a spot got %f, %f, %f; expected -1, -1, 0", cosInner, cosOuter, lightRange)
+	}
+
+	cosInner, cosOuter, lightRange = getSpotLightUniforms(&Light{InnerAngle: 0.0, OuterAngle: 90.0, Range: 10.0})
+	if cosInner != 1.0 || cosOuter > 1e-6 || cosOuter < -1e-6 || lightRange != 10.0 {
+		t.Errorf("The spot light got %f, %f, %f; expected 1, 0, 10", cosInner, cosOuter, lightRange)
+	}
+}
@@ -4,30 +4,81 @@
 package fizzle
 
 import (
+	"fmt"
+
 	graphics "github.com/tbogdala/fizzle/graphicsprovider"
 )
 
+const (
+	// DefaultMaxMipLevel is the OpenGL default for the highest mipmap level
+	// of a texture that will be sampled.
+	DefaultMaxMipLevel = 1000
+)
+
+// TextureMipSettings are the mipmap sampling parameters for a texture.
+type TextureMipSettings struct {
+	// MipBias is added to the level of detail when choosing a mipmap level;
+	// negative values use sharper mipmap levels at a distance.
+	MipBias float32
+
+	// MinMipLevel is the lowest (largest) mipmap level that will be sampled.
+	MinMipLevel int
+
+	// MaxMipLevel is the highest (smallest) mipmap level that will be sampled.
+	MaxMipLevel int
+}
+
 // TextureManager provides an easy way to load textures to OpenGL and
 // to access the textures by name elsewhere.
 type TextureManager struct {
 	// storage keeps references to the OpenGL texture objects referenced by name.
 	storage map[string]graphics.Texture
+
+	// mipSettings keeps the mipmap parameters set for textures by name.
+	mipSettings map[string]TextureMipSettings
+
+	// mipLevelCounts keeps the number of mipmap levels a full mipmap chain
+	// has for the size of each texture by name.
+	mipLevelCounts map[string]int
+
+	// gfx is the GraphicsProvider set with SetGraphics() or nil to use the
+	// one set for the package.
+	gfx graphics.GraphicsProvider
 }
 
 // NewTextureManager creates a new TextureManager object with empty storage.
 func NewTextureManager() *TextureManager {
 	tm := new(TextureManager)
 	tm.storage = make(map[string]graphics.Texture)
+	tm.mipSettings = make(map[string]TextureMipSettings)
+	tm.mipLevelCounts = make(map[string]int)
 	return tm
 }
 
+// SetGraphics sets the GraphicsProvider the TextureManager uses instead of
+// the one set for the package with fizzle.SetGraphics().
+func (tm *TextureManager) SetGraphics(g graphics.GraphicsProvider) {
+	tm.gfx = g
+}
+
+// getGraphics returns the GraphicsProvider for the TextureManager to use.
+func (tm *TextureManager) getGraphics() graphics.GraphicsProvider {
+	if tm.gfx != nil {
+		return tm.gfx
+	}
+	return gfx
+}
+
 // Destroy deletes all of the stored textures from OpenGL
 // and resets the storage map.
 func (tm *TextureManager) Destroy() {
+	g := tm.getGraphics()
 	for _, t := range tm.storage {
-		gfx.DeleteTexture(t)
+		g.DeleteTexture(t)
 	}
 	tm.storage = make(map[string]graphics.Texture)
+	tm.mipSettings = make(map[string]TextureMipSettings)
+	tm.mipLevelCounts = make(map[string]int)
 }
 
 // GetTexture attempts to access the texture by name in storage and returns
@@ -38,16 +89,155 @@ func (tm *TextureManager) GetTexture(keyToUse string) (graphics.Texture, bool) {
 	return glTexture, okay
 }
 
+// DeleteTexture deletes the texture from OpenGL. It should only be used for
+// textures no longer in storage, such as the old texture after reloading one.
+func (tm *TextureManager) DeleteTexture(glTexture graphics.Texture) {
+	tm.getGraphics().DeleteTexture(glTexture)
+}
+
 // LoadTexture loads a texture specified by path into OpenGL and then
 // stores the object in the storage map under the specified keyToUse.
+// If mipmap parameters were set for a texture previously stored under
+// keyToUse, they are applied to the new texture.
 func (tm *TextureManager) LoadTexture(keyToUse string, path string) (graphics.Texture, error) {
 	// load the file into a GL texture
-	glTexture, err := LoadImageToTexture(path)
+	g := tm.getGraphics()
+	glTexture := g.GenTexture()
+	width, height, err := loadImageFileToTexture(g, glTexture, path)
 	if err != nil {
 		return glTexture, err
 	}
 
 	// store it for later
 	tm.storage[keyToUse] = glTexture
+	tm.mipLevelCounts[keyToUse] = getMipLevelCount(width, height)
+	if settings, okay := tm.mipSettings[keyToUse]; okay {
+		tm.applyMipSettings(glTexture, settings)
+	}
 	return glTexture, nil
 }
+
+// ReloadTexture loads the image at path into the texture already stored under
+// keyToUse so that everything holding the texture object sees the new image.
+// The mipmap parameters set for the texture are applied again. If there is no
+// texture stored under keyToUse, the image is loaded with LoadTexture instead.
+func (tm *TextureManager) ReloadTexture(keyToUse string, path string) error {
+	glTexture, okay := tm.storage[keyToUse]
+	if !okay {
+		_, err := tm.LoadTexture(keyToUse, path)
+		return err
+	}
+
+	width, height, err := loadImageFileToTexture(tm.getGraphics(), glTexture, path)
+	if err != nil {
+		return err
+	}
+
+	tm.mipLevelCounts[keyToUse] = getMipLevelCount(width, height)
+	if settings, okay := tm.mipSettings[keyToUse]; okay {
+		tm.applyMipSettings(glTexture, settings)
+	}
+	return nil
+}
+
+// getMipLevelCount returns the number of levels in a full mipmap chain for a
+// texture of the size specified, including the base level.
+func getMipLevelCount(width, height int32) int {
+	size := width
+	if height > size {
+		size = height
+	}
+
+	count := 1
+	for size > 1 {
+		size /= 2
+		count++
+	}
+	return count
+}
+
+// GetMipLevelCount returns the number of levels in a full mipmap chain for the
+// texture by name, including the base level. The bool returned indicates if the
+// texture was found in storage.
+func (tm *TextureManager) GetMipLevelCount(keyToUse string) (int, bool) {
+	if _, okay := tm.storage[keyToUse]; !okay {
+		return 0, false
+	}
+
+	count, okay := tm.mipLevelCounts[keyToUse]
+	if !okay {
+		// the size is unknown so allow every level
+		count = DefaultMaxMipLevel + 1
+	}
+	return count, true
+}
+
+// applyMipSettings sets the mipmap parameters on the texture object.
+func (tm *TextureManager) applyMipSettings(glTexture graphics.Texture, settings TextureMipSettings) {
+	g := tm.getGraphics()
+	g.BindTexture(graphics.TEXTURE_2D, glTexture)
+	g.TexParameterf(graphics.TEXTURE_2D, graphics.TEXTURE_LOD_BIAS, settings.MipBias)
+	g.TexParameteri(graphics.TEXTURE_2D, graphics.TEXTURE_BASE_LEVEL, int32(settings.MinMipLevel))
+	g.TexParameteri(graphics.TEXTURE_2D, graphics.TEXTURE_MAX_LEVEL, int32(settings.MaxMipLevel))
+	g.BindTexture(graphics.TEXTURE_2D, 0)
+}
+
+// GetMipSettings returns the mipmap parameters for the texture by name. Textures
+// that haven't had any set return the OpenGL defaults. The bool returned indicates
+// if the texture was found in storage.
+func (tm *TextureManager) GetMipSettings(keyToUse string) (TextureMipSettings, bool) {
+	if _, okay := tm.storage[keyToUse]; !okay {
+		return TextureMipSettings{}, false
+	}
+
+	settings, okay := tm.mipSettings[keyToUse]
+	if !okay {
+		settings.MaxMipLevel = DefaultMaxMipLevel
+	}
+	return settings, true
+}
+
+// SetMipBias sets the level of detail bias for the texture by name which shifts
+// the mipmap level chosen when sampling. Negative values make textures sharper
+// at a distance, which is useful with anisotropic filtering, at the cost of
+// shimmering; positive values make them blurrier.
+func (tm *TextureManager) SetMipBias(keyToUse string, bias float32) error {
+	glTexture, okay := tm.storage[keyToUse]
+	if !okay {
+		return fmt.Errorf("Failed to set the mip bias for texture %s because it is not in storage.", keyToUse)
+	}
+
+	g := tm.getGraphics()
+	g.BindTexture(graphics.TEXTURE_2D, glTexture)
+	g.TexParameterf(graphics.TEXTURE_2D, graphics.TEXTURE_LOD_BIAS, bias)
+	g.BindTexture(graphics.TEXTURE_2D, 0)
+
+	settings, _ := tm.GetMipSettings(keyToUse)
+	settings.MipBias = bias
+	tm.mipSettings[keyToUse] = settings
+	return nil
+}
+
+// SetMinMaxMipLevel limits the mipmap levels that will be sampled for the texture
+// by name to the range [minLevel..maxLevel].
+func (tm *TextureManager) SetMinMaxMipLevel(keyToUse string, minLevel, maxLevel int) error {
+	glTexture, okay := tm.storage[keyToUse]
+	if !okay {
+		return fmt.Errorf("Failed to set the mip levels for texture %s because it is not in storage.", keyToUse)
+	}
+	if minLevel < 0 || maxLevel < minLevel {
+		return fmt.Errorf("Invalid mip level range %d to %d for texture %s.", minLevel, maxLevel, keyToUse)
+	}
+
+	g := tm.getGraphics()
+	g.BindTexture(graphics.TEXTURE_2D, glTexture)
+	g.TexParameteri(graphics.TEXTURE_2D, graphics.TEXTURE_BASE_LEVEL, int32(minLevel))
+	g.TexParameteri(graphics.TEXTURE_2D, graphics.TEXTURE_MAX_LEVEL, int32(maxLevel))
+	g.BindTexture(graphics.TEXTURE_2D, 0)
+
+	settings, _ := tm.GetMipSettings(keyToUse)
+	settings.MinMipLevel = minLevel
+	settings.MaxMipLevel = maxLevel
+	tm.mipSettings[keyToUse] = settings
+	return nil
+}
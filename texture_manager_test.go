@@ -0,0 +1,215 @@
+// Copyright 2016, Timothy Bogdala <tdb@animal-machine.com>
+// See the LICENSE file for more details.
+
+package fizzle
+
+import (
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"unsafe"
+
+	graphics "github.com/tbogdala/fizzle/graphicsprovider"
+)
+
+// mockTextureGraphics records the texture parameters set on each texture
+// object. Calling any GraphicsProvider method it doesn't implement panics.
+type mockTextureGraphics struct {
+	graphics.GraphicsProvider
+
+	nextTexture graphics.Texture
+	bound       graphics.Texture
+	floatParams map[graphics.Texture]map[graphics.Enum]float32
+	intParams   map[graphics.Texture]map[graphics.Enum]int32
+	deleted     []graphics.Texture
+}
+
+func newMockTextureGraphics() *mockTextureGraphics {
+	g := new(mockTextureGraphics)
+	g.nextTexture = 1
+	g.floatParams = make(map[graphics.Texture]map[graphics.Enum]float32)
+	g.intParams = make(map[graphics.Texture]map[graphics.Enum]int32)
+	return g
+}
+
+func (g *mockTextureGraphics) GenTexture() graphics.Texture {
+	t := g.nextTexture
+	g.nextTexture++
+	g.floatParams[t] = make(map[graphics.Enum]float32)
+	g.intParams[t] = make(map[graphics.Enum]int32)
+	return t
+}
+
+func (g *mockTextureGraphics) ActiveTexture(t graphics.Texture)                     {}
+func (g *mockTextureGraphics) BindTexture(target graphics.Enum, t graphics.Texture) { g.bound = t }
+func (g *mockTextureGraphics) DeleteTexture(t graphics.Texture)                     { g.deleted = append(g.deleted, t) }
+func (g *mockTextureGraphics) Ptr(data interface{}) unsafe.Pointer                  { return nil }
+
+func (g *mockTextureGraphics) TexImage2D(target graphics.Enum, level, intfmt, width, height, border int32, format graphics.Enum, ty graphics.Enum, ptr unsafe.Pointer, dataLength int) {
+}
+
+func (g *mockTextureGraphics) TexParameterf(target, pname graphics.Enum, param float32) {
+	g.floatParams[g.bound][pname] = param
+}
+
+func (g *mockTextureGraphics) TexParameteri(target, pname graphics.Enum, param int32) {
+	g.intParams[g.bound][pname] = param
+}
+
+// writeTestPNG writes a blank PNG image of the size specified.
+func writeTestPNG(t *testing.T, path string, width, height int) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create the test image: %v", err)
+	}
+	defer f.Close()
+	err = png.Encode(f, image.NewNRGBA(image.Rect(0, 0, width, height)))
+	if err != nil {
+		t.Fatalf("Failed to encode the test image: %v", err)
+	}
+}
+
+func TestTextureManagerMipSettings(t *testing.T) {
+	g := newMockTextureGraphics()
+	tm := NewTextureManager()
+	tm.SetGraphics(g)
+	tex := g.GenTexture()
+	tm.storage["test"] = tex
+
+	settings, okay := tm.GetMipSettings("test")
+	if !okay || settings.MipBias != 0.0 || settings.MinMipLevel != 0 || settings.MaxMipLevel != DefaultMaxMipLevel {
+		t.Errorf("New textures should have the default mip settings; got %v", settings)
+	}
+
+	err := tm.SetMipBias("test", -1.5)
+	if err != nil || g.floatParams[tex][graphics.TEXTURE_LOD_BIAS] != -1.5 {
+		t.Errorf("SetMipBias didn't set the LOD bias on the texture (%v)", err)
+	}
+	err = tm.SetMinMaxMipLevel("test", 1, 4)
+	if err != nil || g.intParams[tex][graphics.TEXTURE_BASE_LEVEL] != 1 || g.intParams[tex][graphics.TEXTURE_MAX_LEVEL] != 4 {
+		t.Errorf("SetMinMaxMipLevel didn't set the mip levels on the texture (%v)", err)
+	}
+	if g.bound != 0 {
+		t.Errorf("The texture was left bound.")
+	}
+
+	settings, _ = tm.GetMipSettings("test")
+	if settings != (TextureMipSettings{MipBias: -1.5, MinMipLevel: 1, MaxMipLevel: 4}) {
+		t.Errorf("The mip settings were not stored; got %v", settings)
+	}
+
+	if tm.SetMipBias("missing", 1.0) == nil || tm.SetMinMaxMipLevel("missing", 0, 1) == nil {
+		t.Errorf("Setting mip parameters for a texture not in storage should fail.")
+	}
+	if tm.SetMinMaxMipLevel("test", -1, 2) == nil || tm.SetMinMaxMipLevel("test", 3, 2) == nil {
+		t.Errorf("An invalid mip level range should fail.")
+	}
+	if _, okay := tm.GetMipSettings("missing"); okay {
+		t.Errorf("GetMipSettings found a texture that isn't in storage.")
+	}
+}
+
+func TestTextureManagerReloadKeepsMipSettings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fizzle_textures")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	texPath := filepath.Join(dir, "test.png")
+	writeTestPNG(t, texPath, 256, 64)
+
+	g := newMockTextureGraphics()
+	tm := NewTextureManager()
+	tm.SetGraphics(g)
+
+	_, err = tm.LoadTexture("test", texPath)
+	if err != nil {
+		t.Fatalf("Failed to load the texture: %v", err)
+	}
+	if count, _ := tm.GetMipLevelCount("test"); count != 9 {
+		t.Errorf("A 256x64 texture has %d mip levels; expected 9", count)
+	}
+	tm.SetMipBias("test", 2.0)
+	tm.SetMinMaxMipLevel("test", 2, 5)
+
+	reloaded, err := tm.LoadTexture("test", texPath)
+	if err != nil {
+		t.Fatalf("Failed to reload the texture: %v", err)
+	}
+	if g.floatParams[reloaded][graphics.TEXTURE_LOD_BIAS] != 2.0 ||
+		g.intParams[reloaded][graphics.TEXTURE_BASE_LEVEL] != 2 ||
+		g.intParams[reloaded][graphics.TEXTURE_MAX_LEVEL] != 5 {
+		t.Errorf("The mip settings were not applied to the reloaded texture.")
+	}
+}
+
+func TestTextureManagerReloadTextureInPlace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fizzle_textures")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	texPath := filepath.Join(dir, "test.png")
+	writeTestPNG(t, texPath, 64, 64)
+
+	g := newMockTextureGraphics()
+	tm := NewTextureManager()
+	tm.SetGraphics(g)
+
+	original, err := tm.LoadTexture("test", texPath)
+	if err != nil {
+		t.Fatalf("Failed to load the texture: %v", err)
+	}
+	tm.SetMipBias("test", -1.0)
+
+	writeTestPNG(t, texPath, 16, 16)
+	err = tm.ReloadTexture("test", texPath)
+	if err != nil {
+		t.Fatalf("Failed to reload the texture: %v", err)
+	}
+	if tex, _ := tm.GetTexture("test"); tex != original {
+		t.Errorf("ReloadTexture stored texture %d; expected the original texture %d", tex, original)
+	}
+	if len(g.deleted) != 0 {
+		t.Errorf("ReloadTexture deleted textures %v; expected none", g.deleted)
+	}
+	if count, _ := tm.GetMipLevelCount("test"); count != 5 {
+		t.Errorf("A reloaded 16x16 texture has %d mip levels; expected 5", count)
+	}
+	if g.floatParams[original][graphics.TEXTURE_LOD_BIAS] != -1.0 {
+		t.Errorf("The mip bias was not applied to the reloaded texture.")
+	}
+
+	// textures not in storage yet get loaded
+	err = tm.ReloadTexture("other", texPath)
+	if err != nil {
+		t.Fatalf("Failed to reload a texture not in storage: %v", err)
+	}
+	if _, okay := tm.GetTexture("other"); !okay {
+		t.Errorf("ReloadTexture didn't store a texture that wasn't in storage.")
+	}
+}
+
+func TestGetMipLevelCount(t *testing.T) {
+	tests := []struct {
+		width, height int32
+		expected      int
+	}{
+		{1, 1, 1},
+		{2, 2, 2},
+		{1024, 1024, 11},
+		{1024, 16, 11},
+		{16, 1024, 11},
+		{300, 20, 9},
+	}
+
+	for _, test := range tests {
+		count := getMipLevelCount(test.width, test.height)
+		if count != test.expected {
+			t.Errorf("getMipLevelCount(%d, %d) returned %d; expected %d", test.width, test.height, count, test.expected)
+		}
+	}
+}
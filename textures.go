@@ -130,23 +130,30 @@ func LoadRGBToTextureExt(rgb []byte, imageSize, magFilter, minFilter, wrapS, wra
 // LoadImageToTexture loads an image from a file into an OpenGL texture.
 func LoadImageToTexture(filePath string) (graphics.Texture, error) {
 	tex := gfx.GenTexture()
-	gfx.ActiveTexture(graphics.TEXTURE0)
-	gfx.BindTexture(graphics.TEXTURE_2D, tex)
-	gfx.TexParameteri(graphics.TEXTURE_2D, graphics.TEXTURE_MAG_FILTER, graphics.LINEAR)
-	gfx.TexParameteri(graphics.TEXTURE_2D, graphics.TEXTURE_MIN_FILTER, graphics.LINEAR)
-	gfx.TexParameteri(graphics.TEXTURE_2D, graphics.TEXTURE_WRAP_S, graphics.REPEAT)
-	gfx.TexParameteri(graphics.TEXTURE_2D, graphics.TEXTURE_WRAP_T, graphics.REPEAT)
+	_, _, err := loadImageFileToTexture(gfx, tex, filePath)
+	return tex, err
+}
+
+// loadImageFileToTexture loads an image from a file into the texture object
+// using the GraphicsProvider specified. The size of the image is returned.
+func loadImageFileToTexture(g graphics.GraphicsProvider, tex graphics.Texture, filePath string) (int32, int32, error) {
+	g.ActiveTexture(graphics.TEXTURE0)
+	g.BindTexture(graphics.TEXTURE_2D, tex)
+	g.TexParameteri(graphics.TEXTURE_2D, graphics.TEXTURE_MAG_FILTER, graphics.LINEAR)
+	g.TexParameteri(graphics.TEXTURE_2D, graphics.TEXTURE_MIN_FILTER, graphics.LINEAR)
+	g.TexParameteri(graphics.TEXTURE_2D, graphics.TEXTURE_WRAP_S, graphics.REPEAT)
+	g.TexParameteri(graphics.TEXTURE_2D, graphics.TEXTURE_WRAP_T, graphics.REPEAT)
 
 	rgbaFlipped, err := loadFile(filePath)
 	if err != nil {
-		return tex, err
+		return 0, 0, err
 	}
 
 	imageSizeW := int32(rgbaFlipped.Bounds().Max.X)
 	imageSizeH := int32(rgbaFlipped.Bounds().Max.Y)
 
-	gfx.TexImage2D(graphics.TEXTURE_2D, 0, graphics.RGBA, imageSizeW, imageSizeH, 0, graphics.RGBA, graphics.UNSIGNED_BYTE, gfx.Ptr(rgbaFlipped.Pix), len(rgbaFlipped.Pix))
-	return tex, nil
+	g.TexImage2D(graphics.TEXTURE_2D, 0, graphics.RGBA, imageSizeW, imageSizeH, 0, graphics.RGBA, graphics.UNSIGNED_BYTE, g.Ptr(rgbaFlipped.Pix), len(rgbaFlipped.Pix))
+	return imageSizeW, imageSizeH, nil
 }
 
 // LoadPNGToTexture loads a byte slice as a PNG image and buffers it into